@@ -0,0 +1,79 @@
+// Command ingest loads a csv or ndjson export of the submittal log into submittal_log_facts.
+//
+//	go run ./cmd/ingest [-format csv|ndjson] export.csv
+//
+// The format defaults to the file extension, - reads stdin. Rows that cannot be imported are printed
+// and the command exits with status 1, the other rows are still imported.
+package main
+
+import (
+	"database/sql"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"go-lambda-graphql/config"
+	"go-lambda-graphql/services/ingest"
+
+	_ "github.com/lib/pq"
+)
+
+func main() {
+	format := flag.String("format", "", "csv or ndjson, defaults to the file extension")
+	flag.Parse()
+	if flag.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: ingest [-format csv|ndjson] file")
+		os.Exit(2)
+	}
+	if err := run(flag.Arg(0), *format); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(path, format string) error {
+	var in io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	}
+	if format == "" {
+		format = strings.TrimPrefix(filepath.Ext(path), ".")
+	}
+	var r ingest.Reader
+	switch strings.ToLower(format) {
+	case "csv":
+		var err error
+		if r, err = ingest.NewCSVReader(in); err != nil {
+			return err
+		}
+	case "ndjson", "jsonl":
+		r = ingest.NewNDJSONReader(in)
+	default:
+		return fmt.Errorf("unknown format %q, use -format csv or -format ndjson", format)
+	}
+	db, err := sql.Open("postgres", config.ConnectionString)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	report, err := ingest.Import(db, r)
+	if err != nil {
+		return err
+	}
+	for _, e := range report.Errors {
+		fmt.Fprintln(os.Stderr, e)
+	}
+	fmt.Printf("read %d rows, imported %d submittals, skipped %d rows\n", report.Rows, report.Imported, len(report.Errors))
+	if len(report.Errors) > 0 {
+		return fmt.Errorf("%d rows were skipped", len(report.Errors))
+	}
+	return nil
+}
@@ -2,28 +2,111 @@ package config
 
 import (
 	"flag"
-	"go-lambda-graphql/services/generate"
+	"os"
+	"strconv"
+	"time"
 )
 
 // Port defines server listening port
 var Port string
 
-// IsProduction describes server development mode
+// IsProduction describes server development mode, it is set by PRODUCTION=true and masks internal errors
 var IsProduction bool
 
 // ConnectionString from db connection string
 var ConnectionString string
 
-// JWT secret
-var JWTSecret string
+// JWTKeys is the json encoded jwt signing key set, see services/keys
+var JWTKeys string
+
+// JWTKeysFile is the path to a json encoded jwt signing key set
+var JWTKeysFile string
+
+// JWTIssuer is the iss claim of every access token
+var JWTIssuer string
+
+// JWTAudience is the aud claim of every access token
+var JWTAudience string
+
+// AccessTokenTTL is how long an access token is valid
+var AccessTokenTTL time.Duration
+
+// RefreshTokenTTL is how long a refresh token can be exchanged for a new access token
+var RefreshTokenTTL time.Duration
+
+// AuthCookie is the name of the HttpOnly cookie carrying the access token, it must be set with SameSite=Strict
+var AuthCookie string
+
+// PersistedQueriesDir keeps the persisted queries as files, they are stored in postgres when it is empty,
+// it is only read on lambda
+var PersistedQueriesDir string
+
+// QueryCacheMaxAge is how long the successful responses to GET /query may be cached
+var QueryCacheMaxAge time.Duration
+
+// MaxQueryDepth is how deeply an operation can nest its fields
+var MaxQueryDepth int
+
+// MaxQueryCost is the largest cost an operation can have, see the @cost directive
+var MaxQueryCost int
 
 // Directory represents http fileserver directory
 var Directory string
 
+// IsLambda describes whether the server is running inside aws lambda
+var IsLambda bool
+
 func init() {
 	Directory = *flag.String("d", "webapp/build", "the directory of static file to host")
-	JWTSecret = generate.GenerateRandomString(64)
-	ConnectionString = "user=williamhuang dbname=lambda sslmode=disable"
-	IsProduction = *flag.Bool("p", false, "production mode?")
 	Port = *flag.String("port", "3001", "listening port")
+	load()
+}
+
+// load reads the environment
+func load() {
+	JWTKeys = os.Getenv("JWT_KEYS")
+	JWTKeysFile = os.Getenv("JWT_KEYS_FILE")
+	JWTIssuer = getEnv("JWT_ISSUER", "go-lambda-graphql")
+	JWTAudience = getEnv("JWT_AUDIENCE", "go-lambda-graphql")
+	AuthCookie = getEnv("AUTH_COOKIE", "jwt")
+	PersistedQueriesDir = os.Getenv("PERSISTED_QUERIES_DIR")
+	QueryCacheMaxAge = getDuration("QUERY_CACHE_MAX_AGE", time.Minute)
+	MaxQueryDepth = getInt("MAX_QUERY_DEPTH", 10)
+	MaxQueryCost = getInt("MAX_QUERY_COST", 10000)
+	AccessTokenTTL = getDuration("ACCESS_TOKEN_TTL", 15*time.Minute)
+	RefreshTokenTTL = getDuration("REFRESH_TOKEN_TTL", 30*24*time.Hour)
+	ConnectionString = "user=williamhuang dbname=lambda sslmode=disable"
+	IsProduction = getBool("PRODUCTION", false)
+	IsLambda = os.Getenv("AWS_LAMBDA_FUNCTION_NAME") != ""
+}
+
+func getEnv(key string, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
+
+func getDuration(key string, fallback time.Duration) time.Duration {
+	d, err := time.ParseDuration(os.Getenv(key))
+	if err != nil {
+		return fallback
+	}
+	return d
+}
+
+func getInt(key string, fallback int) int {
+	i, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return fallback
+	}
+	return i
+}
+
+func getBool(key string, fallback bool) bool {
+	b, err := strconv.ParseBool(os.Getenv(key))
+	if err != nil {
+		return fallback
+	}
+	return b
 }
@@ -0,0 +1,23 @@
+package config
+
+import (
+	"os"
+	"testing"
+)
+
+func TestProduction(t *testing.T) {
+	defer os.Unsetenv("PRODUCTION")
+	defer load()
+
+	os.Setenv("PRODUCTION", "true")
+	load()
+	if !IsProduction {
+		t.Errorf("expected PRODUCTION=true to enable production mode")
+	}
+
+	os.Unsetenv("PRODUCTION")
+	load()
+	if IsProduction {
+		t.Errorf("expected development mode without PRODUCTION")
+	}
+}
@@ -0,0 +1,84 @@
+package gql
+
+import (
+	"context"
+	"go-lambda-graphql/models"
+
+	. "github.com/volatiletech/sqlboiler/queries/qm"
+)
+
+// membershipCoversSQL matches the memberships m of the company of a submittal, company wide or for its project
+const membershipCoversSQL = `m.company_id = submittal_log_facts.company_id
+	and (m.project_id is null or m.project_id = submittal_log_facts.project_id)`
+
+// submittalVisibleSQL matches the submittals of the companies and projects the user is a member of,
+// private submittals only when the user approves, manages or created them
+const submittalVisibleSQL = `exists (
+	select 1 from membership m
+	where m.usr_id = ? and ` + membershipCoversSQL + `
+	and (submittal_log_facts.private is not true
+		or m.source_user_id = any(submittal_log_facts.approver_ids)
+		or m.source_user_id in (submittal_log_facts.submittal_manager_id, submittal_log_facts.created_by_id))
+)`
+
+// submittalManagedSQL matches the submittals of the companies and projects the user is a manager of
+const submittalManagedSQL = `exists (
+	select 1 from membership m
+	where m.usr_id = ? and m.role = 'manager' and ` + membershipCoversSQL + `
+)`
+
+// submittalAccess returns the mods restricting submittal queries to the rows current may see,
+// users allowed to read every submittal are not restricted
+func submittalAccess(ctx context.Context, current *models.Usr) ([]QueryMod, error) {
+	all, err := hasPermission(ctx, current, permReadAllSubmittals)
+	if err != nil || all {
+		return nil, err
+	}
+	return []QueryMod{Where(submittalVisibleSQL, current.ID)}, nil
+}
+
+// submittalScope returns the mods every submittal query starts from, the rows current may see
+// without the soft deleted ones, unless a user allowed to read them asks for them
+func submittalScope(ctx context.Context, current *models.Usr, includeDeleted *bool) ([]QueryMod, error) {
+	mods, err := submittalAccess(ctx, current)
+	if err != nil {
+		return nil, err
+	}
+	if includeDeleted == nil || !*includeDeleted {
+		return append(mods, Where("deleted_at is null")), nil
+	}
+	deleted, err := hasPermission(ctx, current, permReadDeletedSubmittals)
+	if err != nil {
+		return nil, err
+	}
+	if !deleted {
+		return nil, errForbidden
+	}
+	return mods, nil
+}
+
+// findSubmittal loads a submittal current may see, soft deleted ones included, nil when there is none
+func findSubmittal(ctx context.Context, current *models.Usr, id int64) (*submittalRow, error) {
+	mods, err := submittalAccess(ctx, current)
+	if err != nil {
+		return nil, err
+	}
+	return oneSubmittalRow(append(mods, Where("id = ?", id))...)
+}
+
+// canManageSubmittal reports whether current can delete and restore the submittal
+func canManageSubmittal(ctx context.Context, current *models.Usr, submittal *submittalRow) (bool, error) {
+	all, err := hasPermission(ctx, current, permManageSubmittals)
+	if err != nil || all {
+		return all, err
+	}
+	return models.SubmittalLogFactsG(Where("id = ?", submittal.ID), Where(submittalManagedSQL, current.ID)).Exists()
+}
+
+// sharesCompany reports whether current may follow the user id, themselves or a member of one of their companies
+func sharesCompany(current *models.Usr, id int64) (bool, error) {
+	if current.ID == id {
+		return true, nil
+	}
+	return models.MembershipsG(Where("usr_id = ? and company_id in (select company_id from membership where usr_id = ?)", id, current.ID)).Exists()
+}
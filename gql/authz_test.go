@@ -0,0 +1,130 @@
+package gql
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"go-lambda-graphql/config"
+	"go-lambda-graphql/models"
+	"go-lambda-graphql/services/auth"
+	"go-lambda-graphql/services/generate"
+	"io/ioutil"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/relay"
+	_ "github.com/lib/pq"
+	"github.com/tidwall/gjson"
+	"github.com/volatiletech/sqlboiler/boil"
+)
+
+func TestSubmittalMembership(t *testing.T) {
+	rawSchema, _ := ioutil.ReadFile("schema.gql")
+	schema := MustParseSchema(string(rawSchema))
+	db, _ := sql.Open("postgres", config.ConnectionString)
+	boil.SetDB(db)
+	const company, otherCompany = 900001, 900002
+
+	newUser := func(company int64) *models.Usr {
+		usr := &models.Usr{Name: "Membership Tester", Email: generate.GenerateRandomHexString(8) + "@example.com", PasswordHash: "x"}
+		if err := usr.InsertG(); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := db.Exec("insert into membership (usr_id, company_id) values ($1, $2)", usr.ID, company); err != nil {
+			t.Fatal(err)
+		}
+		return usr
+	}
+	member, outsider := newUser(company), newUser(otherCompany)
+	defer db.Exec("delete from usr where id in ($1, $2)", member.ID, outsider.ID)
+	title := "authz" + generate.GenerateRandomHexString(8)
+	var id int64
+	if err := db.QueryRow("insert into submittal_log_facts (company_id, title, created_at) values ($1, $2, now()) returning id", company, title).Scan(&id); err != nil {
+		t.Fatal(err)
+	}
+	defer db.Exec("delete from submittal_log_facts where id = $1", id)
+	globalID := string(relay.MarshalID("submittal", ID{strconv.FormatInt(id, 10)}))
+
+	// as authenticates usr the way the Authenticate middleware would have
+	as := func(usr *models.Usr) context.Context {
+		ctx := context.WithValue(context.Background(), loadersKey{}, newLoaders())
+		ctx = auth.WithPrincipal(ctx, &auth.Principal{Claims: jwt.MapClaims{"id": float64(usr.ID)}})
+		return withViewer(ctx, usr)
+	}
+	exec := func(usr *models.Usr, query string) gjson.Result {
+		result, _ := json.Marshal(schema.Exec(as(usr), query, "", nil))
+		return gjson.ParseBytes(result)
+	}
+	day := time.Now().UTC()
+	reads := map[string]string{
+		"submittals.totalCount":   `{ submittals(filter: {title: {eq: "` + title + `"}}) { totalCount } }`,
+		"search.totalCount":       `{ search(query: "` + title + `") { totalCount } }`,
+		"submittalTrends.#.total": `{ submittalTrends(event: CREATED, interval: DAY, from: "` + day.AddDate(0, 0, -1).Format("2006-01-02") + `", to: "` + day.AddDate(0, 0, 1).Format("2006-01-02") + `") { total } }`,
+		"node.id":                 `{ node(id: "` + globalID + `") { id } }`,
+	}
+
+	t.Run("let members read the submittals of their company", func(t *testing.T) {
+		for path, query := range reads {
+			if result := exec(member, query).Get("data." + path); !visible(result) {
+				t.Errorf("expected the member to see the submittal through %s, got %s", path, result.Raw)
+			}
+		}
+	})
+
+	t.Run("hide the submittals of another company", func(t *testing.T) {
+		for path, query := range reads {
+			if result := exec(outsider, query).Get("data." + path); visible(result) {
+				t.Errorf("expected %s to hide the submittal from a non member, got %s", path, result.Raw)
+			}
+		}
+	})
+
+	t.Run("refuse to delete the submittals of another company", func(t *testing.T) {
+		result := exec(outsider, `mutation { deleteSubmittal(id: "`+globalID+`") { id } }`)
+		if result.Get("data.deleteSubmittal").Type != gjson.Null || !result.Get("errors").Exists() {
+			t.Errorf("expected the delete to be refused, got %s", result.Raw)
+		}
+		var deleted bool
+		if err := db.QueryRow("select deleted_at is not null from submittal_log_facts where id = $1", id).Scan(&deleted); err != nil || deleted {
+			t.Errorf("expected the submittal to stay, deleted %v, %v", deleted, err)
+		}
+	})
+
+	t.Run("follow the updates of the users of the same company only", func(t *testing.T) {
+		follow := func(usr *models.Usr, id *graphql.ID) error {
+			_, err := (&Resolver{}).UserUpdated(as(usr), struct{ ID *graphql.ID }{ID: id})
+			return err
+		}
+		memberID := relay.MarshalID("usr", ID{strconv.FormatInt(member.ID, 10)})
+		if err := follow(outsider, &memberID); err != errForbidden {
+			t.Errorf("expected a non member to be refused, got %v", err)
+		}
+		if err := follow(member, nil); err == errForbidden {
+			t.Errorf("expected the viewer to follow themselves")
+		}
+		colleague := newUser(company)
+		defer db.Exec("delete from usr where id = $1", colleague.ID)
+		if err := follow(colleague, &memberID); err == errForbidden {
+			t.Errorf("expected a member of the same company to follow the member")
+		}
+	})
+}
+
+// visible reports whether a count, a list of totals or an id shows the submittal
+func visible(result gjson.Result) bool {
+	if result.IsArray() {
+		for _, total := range result.Array() {
+			if total.Int() > 0 {
+				return true
+			}
+		}
+		return false
+	}
+	if result.Type == gjson.Number {
+		return result.Int() > 0
+	}
+	return result.Type == gjson.String
+}
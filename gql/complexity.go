@@ -0,0 +1,58 @@
+package gql
+
+import (
+	"context"
+	"go-lambda-graphql/config"
+	"go-lambda-graphql/services/apperr"
+	"go-lambda-graphql/services/connection"
+	"go-lambda-graphql/services/cost"
+
+	"github.com/graph-gophers/graphql-go/errors"
+)
+
+// fieldCosts maps the types of the schema to their fields, their @cost directives and pagination
+var fieldCosts = cost.Schema{}
+
+// CheckQuery refuses the operations nesting deeper or costing more than the configured limits,
+// and those selecting a field the caller lacks the @auth permission of,
+// it runs before the operation so an expensive or forbidden one never reaches the resolvers
+func CheckQuery(ctx context.Context, document string, operationName string, variables map[string]interface{}) *errors.QueryError {
+	limits := cost.Limits{
+		MaxDepth:        config.MaxQueryDepth,
+		MaxCost:         config.MaxQueryCost,
+		DefaultPageSize: connection.DefaultSize,
+	}
+	err := cost.Check(fieldCosts, limits, document, operationName, variables)
+	if err == nil {
+		return authorizationError(authorize(ctx, document, operationName))
+	}
+	if err.Code == cost.Unreadable {
+		// the schema tells best what is wrong with an invalid document, a valid one is refused all the same
+		if errs := parsedSchema.Validate(document); len(errs) > 0 {
+			return errs[0]
+		}
+		return &errors.QueryError{Message: err.Error(), Extensions: map[string]interface{}{"code": err.Code}}
+	}
+	path := make([]interface{}, len(err.Path))
+	for i, p := range err.Path {
+		path[i] = p
+	}
+	return &errors.QueryError{
+		Message:    err.Error(),
+		Path:       path,
+		Extensions: map[string]interface{}{"code": err.Code, "limit": err.Limit, "value": err.Value},
+	}
+}
+
+// authorizationError turns an error of authorize into a query error, the handlers mask those not from apperr
+func authorizationError(err error) *errors.QueryError {
+	switch e := err.(type) {
+	case nil:
+		return nil
+	case *errors.QueryError:
+		return e
+	case *apperr.Error:
+		return &errors.QueryError{Message: e.Message, ResolverError: e, Extensions: e.Extensions()}
+	}
+	return &errors.QueryError{Message: err.Error(), ResolverError: err}
+}
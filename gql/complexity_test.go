@@ -0,0 +1,35 @@
+package gql
+
+import (
+	"go-lambda-graphql/services/cost"
+	"io/ioutil"
+	"testing"
+)
+
+func TestCostDirectives(t *testing.T) {
+	rawSchema, err := ioutil.ReadFile("schema.gql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	costs, _, err := schemaDirectives(string(rawSchema))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f := costs["Query"]["search"]; f != (cost.Field{Type: "SubmittalSearchConnection", Weight: 10, Paginated: true}) {
+		t.Errorf("unexpected search field %+v", f)
+	}
+	if f := costs["Query"]["submittalStats"]; f != (cost.Field{Type: "SubmittalStatsBucket", Weight: 20}) {
+		t.Errorf("unexpected submittalStats field %+v", f)
+	}
+	if f := costs["User"]["friendsConnection"]; !f.Paginated || f.Type != "UserConnection" {
+		t.Errorf("unexpected friendsConnection field %+v", f)
+	}
+	if _, ok := costs["SubmittalLogFilter"]; ok {
+		t.Errorf("inputs are not output types")
+	}
+	for typeName, fields := range costs {
+		if _, ok := fields["eq"]; ok {
+			t.Errorf("%s got the fields of an input", typeName)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package gql
+
+import (
+	"context"
+	"go-lambda-graphql/services/connection"
+)
+
+// connectionArgs are the relay cursor connection arguments, see services/connection
+type connectionArgs struct {
+	First  *int32
+	After  *string
+	Last   *int32
+	Before *string
+}
+
+// PageInfoResolver struct
+type PageInfoResolver struct {
+	P connection.PageInfo
+}
+
+// HasNextPage returns whether there are edges after the page
+func (r *PageInfoResolver) HasNextPage(ctx context.Context) (bool, error) {
+	return r.P.HasNextPage, nil
+}
+
+// HasPreviousPage returns whether there are edges before the page
+func (r *PageInfoResolver) HasPreviousPage(ctx context.Context) (bool, error) {
+	return r.P.HasPreviousPage, nil
+}
+
+// StartCursor returns the cursor of the first edge
+func (r *PageInfoResolver) StartCursor(ctx context.Context) (*string, error) {
+	return r.P.StartCursor, nil
+}
+
+// EndCursor returns the cursor of the last edge
+func (r *PageInfoResolver) EndCursor(ctx context.Context) (*string, error) {
+	return r.P.EndCursor, nil
+}
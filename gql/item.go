@@ -0,0 +1,371 @@
+package gql
+
+import (
+	"context"
+	"database/sql"
+	"go-lambda-graphql/models"
+	"go-lambda-graphql/services/apperr"
+	"go-lambda-graphql/services/connection"
+	"go-lambda-graphql/services/filter"
+	"regexp"
+	"strconv"
+
+	"github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/relay"
+	. "github.com/volatiletech/sqlboiler/queries/qm"
+)
+
+// validGeohash matches the base32 geohashes, so they can be used as a like prefix as is
+var validGeohash = regexp.MustCompile(`^[0-9b-hjkmnp-z]{1,12}$`)
+
+// trendingShare is what a campaign is ordered by in the trending connection
+const trendingShare = "funded / needed"
+
+// locationInput is the LocationInput input object
+type locationInput struct {
+	Address string
+	Geohash string
+}
+
+// CampaignResolver struct
+type CampaignResolver struct {
+	C *models.Campaign
+}
+
+// CampaignConnectionResolver struct
+type CampaignConnectionResolver struct {
+	E     []*CampaignEdgeResolver
+	P     connection.PageInfo
+	Total int32
+}
+
+// CampaignEdgeResolver struct
+type CampaignEdgeResolver struct {
+	C string
+	N *CampaignResolver
+}
+
+// ItemResolver struct
+type ItemResolver struct {
+	I *models.Item
+}
+
+// ItemConnectionResolver struct
+type ItemConnectionResolver struct {
+	E     []*ItemEdgeResolver
+	P     connection.PageInfo
+	Total int32
+}
+
+// ItemEdgeResolver struct
+type ItemEdgeResolver struct {
+	C string
+	N *ItemResolver
+}
+
+// NameResolver struct
+type NameResolver struct {
+	N *models.ItemName
+}
+
+// NameConnectionResolver struct
+type NameConnectionResolver struct {
+	E []*NameEdgeResolver
+	P connection.PageInfo
+}
+
+// NameEdgeResolver struct
+type NameEdgeResolver struct {
+	C string
+	N *NameResolver
+}
+
+func init() {
+	registerNode("campaign", fetchCampaignNode)
+	registerNode("item", fetchItemNode)
+}
+
+// fetchCampaignNode loads a campaign for the node field, only signed in users can refetch campaigns
+func fetchCampaignNode(ctx context.Context, spec ID) (node, error) {
+	if _, _, err := viewer(ctx, nil); err != nil {
+		return nil, err
+	}
+	id, err := strconv.ParseInt(spec.ID, 10, 64)
+	if err != nil {
+		return nil, apperr.New(apperr.ValidationFailed, "invalid campaign id")
+	}
+	campaign, err := models.FindCampaignG(id)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &CampaignResolver{C: campaign}, nil
+}
+
+// fetchItemNode loads an item for the node field, only signed in users can refetch items
+func fetchItemNode(ctx context.Context, spec ID) (node, error) {
+	if _, _, err := viewer(ctx, nil); err != nil {
+		return nil, err
+	}
+	id, err := strconv.ParseInt(spec.ID, 10, 64)
+	if err != nil {
+		return nil, apperr.New(apperr.ValidationFailed, "invalid item id")
+	}
+	item, err := models.FindItemG(id)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &ItemResolver{I: item}, nil
+}
+
+// TrendingConnection field represents the open campaigns, the ones closest to being funded first
+func (r *UserResolver) TrendingConnection(ctx context.Context, args connectionArgs) (*CampaignConnectionResolver, error) {
+	page, err := connection.NewPage(connection.Args(args), connection.Order{Column: trendingShare, Desc: true})
+	if err != nil {
+		return nil, err
+	}
+	open := Where("funded < needed")
+	total, err := models.CampaignsG(open).Count()
+	if err != nil {
+		return nil, err
+	}
+	campaigns, err := models.CampaignsG(append([]QueryMod{open}, page.Mods()...)...).All()
+	if err != nil {
+		return nil, err
+	}
+	var edges []*CampaignEdgeResolver
+	var cursors []string
+	for _, i := range page.Indexes(len(campaigns)) {
+		campaign := campaigns[i]
+		cursor := connection.Cursor{Value: campaign.Funded / campaign.Needed, ID: campaign.ID}.Encode()
+		cursors = append(cursors, cursor)
+		edges = append(edges, &CampaignEdgeResolver{C: cursor, N: &CampaignResolver{C: campaign}})
+	}
+	return &CampaignConnectionResolver{
+		E:     edges,
+		P:     page.PageInfo(cursors),
+		Total: int32(total),
+	}, nil
+}
+
+// SearchConnection field represents the items having a name that contains the input
+func (r *UserResolver) SearchConnection(ctx context.Context, args struct {
+	Input  string
+	First  *int32
+	After  *string
+	Last   *int32
+	Before *string
+}) (*ItemConnectionResolver, error) {
+	names := filter.New(filter.Columns{"name": filter.Text})
+	contains := names.Contains("name", args.Input)
+	if err := names.Err(); err != nil {
+		return nil, err
+	}
+	page := connection.Args{First: args.First, After: args.After, Last: args.Last, Before: args.Before}
+	return itemConnection(page, Where("id in (select item_id from item_name where "+contains.SQL+")", contains.Args...))
+}
+
+// ScanConnection field represents the items located within the geohash of the location
+func (r *UserResolver) ScanConnection(ctx context.Context, args struct {
+	Location locationInput
+	First    *int32
+	After    *string
+	Last     *int32
+	Before   *string
+}) (*ItemConnectionResolver, error) {
+	if !validGeohash.MatchString(args.Location.Geohash) {
+		return nil, apperr.Errorf(apperr.ValidationFailed, "invalid geohash %s", args.Location.Geohash)
+	}
+	page := connection.Args{First: args.First, After: args.After, Last: args.Last, Before: args.Before}
+	return itemConnection(page, Where("geohash like ?", args.Location.Geohash+"%"))
+}
+
+// itemConnection pages through the items matching where, ordered by id
+func itemConnection(args connection.Args, where QueryMod) (*ItemConnectionResolver, error) {
+	page, err := connection.NewPage(args, connection.Order{Column: "id"})
+	if err != nil {
+		return nil, err
+	}
+	total, err := models.ItemsG(where).Count()
+	if err != nil {
+		return nil, err
+	}
+	items, err := models.ItemsG(append([]QueryMod{where}, page.Mods()...)...).All()
+	if err != nil {
+		return nil, err
+	}
+	var edges []*ItemEdgeResolver
+	var cursors []string
+	for _, i := range page.Indexes(len(items)) {
+		item := items[i]
+		cursor := connection.Cursor{Value: item.ID, ID: item.ID}.Encode()
+		cursors = append(cursors, cursor)
+		edges = append(edges, &ItemEdgeResolver{C: cursor, N: &ItemResolver{I: item}})
+	}
+	return &ItemConnectionResolver{
+		E:     edges,
+		P:     page.PageInfo(cursors),
+		Total: int32(total),
+	}, nil
+}
+
+// ID returns the id from Campaign resolver
+func (r *CampaignResolver) ID(ctx context.Context) (graphql.ID, error) {
+	return relay.MarshalID("campaign", ID{strconv.FormatInt(r.C.ID, 10)}), nil
+}
+
+// Created returns the Created from Campaign resolver
+func (r *CampaignResolver) Created(ctx context.Context) (graphql.Time, error) {
+	return graphql.Time{Time: r.C.CreatedAt}, nil
+}
+
+// Updated returns the Updated from Campaign resolver
+func (r *CampaignResolver) Updated(ctx context.Context) (graphql.Time, error) {
+	return graphql.Time{Time: r.C.UpdatedAt}, nil
+}
+
+// Funded returns the amount funded
+func (r *CampaignResolver) Funded(ctx context.Context) (float64, error) {
+	return r.C.Funded, nil
+}
+
+// Needed returns the amount needed
+func (r *CampaignResolver) Needed(ctx context.Context) (float64, error) {
+	return r.C.Needed, nil
+}
+
+// Result returns the test result of the campaign
+func (r *CampaignResolver) Result(ctx context.Context) (string, error) {
+	return r.C.Result, nil
+}
+
+// Status returns the shipping status of the item
+func (r *CampaignResolver) Status(ctx context.Context) (float64, error) {
+	return r.C.Status, nil
+}
+
+// Edges returns the edges of the Campaign connection
+func (r *CampaignConnectionResolver) Edges(ctx context.Context) (*[]*CampaignEdgeResolver, error) {
+	return &r.E, nil
+}
+
+// PageInfo returns the page info of the Campaign connection
+func (r *CampaignConnectionResolver) PageInfo(ctx context.Context) (*PageInfoResolver, error) {
+	return &PageInfoResolver{P: r.P}, nil
+}
+
+// TotalCount returns the number of campaigns in the whole connection
+func (r *CampaignConnectionResolver) TotalCount(ctx context.Context) (int32, error) {
+	return r.Total, nil
+}
+
+// Cursor returns the cursor of the Campaign edge
+func (r *CampaignEdgeResolver) Cursor(ctx context.Context) (string, error) {
+	return r.C, nil
+}
+
+// Node returns the campaign of the Campaign edge
+func (r *CampaignEdgeResolver) Node(ctx context.Context) (*CampaignResolver, error) {
+	return r.N, nil
+}
+
+// ID returns the id from Item resolver
+func (r *ItemResolver) ID(ctx context.Context) (graphql.ID, error) {
+	return relay.MarshalID("item", ID{strconv.FormatInt(r.I.ID, 10)}), nil
+}
+
+// Created returns the Created from Item resolver
+func (r *ItemResolver) Created(ctx context.Context) (graphql.Time, error) {
+	return graphql.Time{Time: r.I.CreatedAt}, nil
+}
+
+// Updated returns the Updated from Item resolver
+func (r *ItemResolver) Updated(ctx context.Context) (graphql.Time, error) {
+	return graphql.Time{Time: r.I.UpdatedAt}, nil
+}
+
+// Address returns the address of the item
+func (r *ItemResolver) Address(ctx context.Context) (string, error) {
+	return r.I.Address, nil
+}
+
+// Geohash returns the geohash of where the item is
+func (r *ItemResolver) Geohash(ctx context.Context) (string, error) {
+	return r.I.Geohash, nil
+}
+
+// NameConnection field represents the names of the item, ordered by name
+func (r *ItemResolver) NameConnection(ctx context.Context, args connectionArgs) (*NameConnectionResolver, error) {
+	page, err := connection.NewPage(connection.Args(args), connection.Order{Column: "name"})
+	if err != nil {
+		return nil, err
+	}
+	names, err := models.ItemNamesG(append([]QueryMod{Where("item_id = ?", r.I.ID)}, page.Mods()...)...).All()
+	if err != nil {
+		return nil, err
+	}
+	var edges []*NameEdgeResolver
+	var cursors []string
+	for _, i := range page.Indexes(len(names)) {
+		name := names[i]
+		cursor := connection.Cursor{Value: name.Name, ID: name.ID}.Encode()
+		cursors = append(cursors, cursor)
+		edges = append(edges, &NameEdgeResolver{C: cursor, N: &NameResolver{N: name}})
+	}
+	return &NameConnectionResolver{E: edges, P: page.PageInfo(cursors)}, nil
+}
+
+// Edges returns the edges of the Item connection
+func (r *ItemConnectionResolver) Edges(ctx context.Context) (*[]*ItemEdgeResolver, error) {
+	return &r.E, nil
+}
+
+// PageInfo returns the page info of the Item connection
+func (r *ItemConnectionResolver) PageInfo(ctx context.Context) (*PageInfoResolver, error) {
+	return &PageInfoResolver{P: r.P}, nil
+}
+
+// TotalCount returns the number of items in the whole connection
+func (r *ItemConnectionResolver) TotalCount(ctx context.Context) (int32, error) {
+	return r.Total, nil
+}
+
+// Cursor returns the cursor of the Item edge
+func (r *ItemEdgeResolver) Cursor(ctx context.Context) (string, error) {
+	return r.C, nil
+}
+
+// Node returns the item of the Item edge
+func (r *ItemEdgeResolver) Node(ctx context.Context) (*ItemResolver, error) {
+	return r.N, nil
+}
+
+// Name returns the name
+func (r *NameResolver) Name(ctx context.Context) (string, error) {
+	return r.N.Name, nil
+}
+
+// Edges returns the edges of the Name connection
+func (r *NameConnectionResolver) Edges(ctx context.Context) (*[]*NameEdgeResolver, error) {
+	return &r.E, nil
+}
+
+// PageInfo returns the page info of the Name connection
+func (r *NameConnectionResolver) PageInfo(ctx context.Context) (*PageInfoResolver, error) {
+	return &PageInfoResolver{P: r.P}, nil
+}
+
+// Cursor returns the cursor of the Name edge
+func (r *NameEdgeResolver) Cursor(ctx context.Context) (string, error) {
+	return r.C, nil
+}
+
+// Node returns the name of the Name edge
+func (r *NameEdgeResolver) Node(ctx context.Context) (*NameResolver, error) {
+	return r.N, nil
+}
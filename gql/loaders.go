@@ -0,0 +1,78 @@
+package gql
+
+import (
+	"context"
+	"go-lambda-graphql/models"
+	"go-lambda-graphql/services/loader"
+	"net/http"
+	"time"
+
+	"github.com/lib/pq"
+	. "github.com/volatiletech/sqlboiler/queries/qm"
+)
+
+type loadersKey struct{}
+
+// Loaders holds the batching loaders of a single request
+type Loaders struct {
+	Users       *loader.Loader
+	Permissions *loader.Loader
+}
+
+// newLoaders returns empty loaders, they must not be shared between requests
+func newLoaders() *Loaders {
+	return &Loaders{
+		Users:       loader.New(fetchUsers, 2*time.Millisecond, 500),
+		Permissions: loader.New(fetchPermissions, 2*time.Millisecond, 500),
+	}
+}
+
+// AttachLoaders gives every request its own set of batching loaders
+func AttachLoaders(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), loadersKey{}, newLoaders())
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// loadersFromContext returns the request loaders, or fresh ones when the request did not go through AttachLoaders
+func loadersFromContext(ctx context.Context) *Loaders {
+	if l, ok := ctx.Value(loadersKey{}).(*Loaders); ok {
+		return l
+	}
+	return newLoaders()
+}
+
+// fetchUsers loads a batch of usr rows in a single query
+func fetchUsers(ids []int64) ([]interface{}, []error) {
+	values := make([]interface{}, len(ids))
+	errs := make([]error, len(ids))
+	usrs, err := models.UsrsG(Where("id = ANY(?)", pq.Array(ids))).All()
+	if err != nil {
+		for i := range errs {
+			errs[i] = err
+		}
+		return values, errs
+	}
+	byID := make(map[int64]*models.Usr, len(usrs))
+	for _, usr := range usrs {
+		byID[usr.ID] = usr
+	}
+	for i, id := range ids {
+		if usr, ok := byID[id]; ok {
+			values[i] = usr
+		} else {
+			errs[i] = errUserNotFound
+		}
+	}
+	return values, errs
+}
+
+// loadUser returns the usr row with the given id, batched with every other user loaded in the request
+func loadUser(ctx context.Context, id int64) (*models.Usr, error) {
+	value, err := loadersFromContext(ctx).Users.Load(id)
+	if err != nil {
+		return nil, err
+	}
+	return value.(*models.Usr), nil
+}
@@ -0,0 +1,160 @@
+package gql
+
+import (
+	"context"
+	"database/sql"
+	"go-lambda-graphql/models"
+	"strconv"
+	"strings"
+
+	"github.com/graph-gophers/graphql-go"
+	. "github.com/volatiletech/sqlboiler/queries/qm"
+	"gopkg.in/volatiletech/null.v6"
+)
+
+// MembershipResolver struct
+type MembershipResolver struct {
+	M *models.Membership
+}
+
+// Memberships returns the companies and projects the user belongs to, only the user itself and membership managers can see them
+func (r *UserResolver) Memberships(ctx context.Context) ([]*MembershipResolver, error) {
+	current, _, err := viewer(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	id, err := r.U.rowID()
+	if err != nil {
+		return nil, err
+	}
+	if current.ID != id {
+		manager, err := hasPermission(ctx, current, permManageMemberships)
+		if err != nil {
+			return nil, err
+		}
+		if !manager {
+			return nil, errForbidden
+		}
+	}
+	memberships, err := models.MembershipsG(Where("usr_id = ?", id), OrderBy("company_id, project_id nulls first")).All()
+	if err != nil {
+		return nil, err
+	}
+	l := make([]*MembershipResolver, len(memberships))
+	for i, m := range memberships {
+		l[i] = &MembershipResolver{M: m}
+	}
+	return l, nil
+}
+
+// AddMembership mutation gives a user a role on a whole company or on one of its projects.
+// Adding an existing membership updates its role and source user
+func (r *Resolver) AddMembership(ctx context.Context, args struct {
+	UserID       graphql.ID
+	CompanyID    graphql.ID
+	ProjectID    *graphql.ID
+	Role         string
+	SourceUserID *graphql.ID
+}) (*MembershipResolver, error) {
+	usrID, err := parseUserID(args.UserID)
+	if err != nil {
+		return nil, err
+	}
+	companyID, err := parseID(args.CompanyID)
+	if err != nil {
+		return nil, err
+	}
+	projectID, err := parseNullID(args.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	sourceUserID, err := parseNullID(args.SourceUserID)
+	if err != nil {
+		return nil, err
+	}
+	role := strings.ToLower(args.Role)
+	membership, err := models.MembershipsG(
+		Where("usr_id = ? and company_id = ? and coalesce(project_id, 0) = ?", usrID, companyID, projectID.Int64),
+	).One()
+	if err == sql.ErrNoRows {
+		membership = &models.Membership{
+			UsrID:        usrID,
+			CompanyID:    companyID,
+			ProjectID:    projectID,
+			Role:         role,
+			SourceUserID: sourceUserID,
+		}
+		err = membership.InsertG()
+	} else if err == nil {
+		membership.Role = role
+		membership.SourceUserID = sourceUserID
+		err = membership.UpdateG("role", "source_user_id", "updated_at")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &MembershipResolver{M: membership}, nil
+}
+
+// RemoveMembership mutation takes a membership away
+func (r *Resolver) RemoveMembership(ctx context.Context, args struct {
+	ID graphql.ID
+}) (bool, error) {
+	id, err := parseID(args.ID)
+	if err != nil {
+		return false, err
+	}
+	membership, err := models.FindMembershipG(id)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if err := membership.DeleteG(); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// parseNullID reads an optional raw bigint id
+func parseNullID(id *graphql.ID) (null.Int64, error) {
+	if id == nil {
+		return null.Int64{}, nil
+	}
+	i, err := parseID(*id)
+	if err != nil {
+		return null.Int64{}, err
+	}
+	return null.Int64From(i), nil
+}
+
+// ID returns the id of the membership
+func (r *MembershipResolver) ID(ctx context.Context) (graphql.ID, error) {
+	return graphql.ID(strconv.FormatInt(r.M.ID, 10)), nil
+}
+
+// CompanyID returns the company of the membership
+func (r *MembershipResolver) CompanyID(ctx context.Context) (graphql.ID, error) {
+	return graphql.ID(strconv.FormatInt(r.M.CompanyID, 10)), nil
+}
+
+// ProjectID returns the project of the membership, null for a company wide membership
+func (r *MembershipResolver) ProjectID(ctx context.Context) (*graphql.ID, error) {
+	return nullID(r.M.ProjectID), nil
+}
+
+// Role returns the role of the member
+func (r *MembershipResolver) Role(ctx context.Context) (string, error) {
+	return strings.ToUpper(r.M.Role), nil
+}
+
+// SourceUserID returns the id of the member in the submittal log export
+func (r *MembershipResolver) SourceUserID(ctx context.Context) (*graphql.ID, error) {
+	return nullID(r.M.SourceUserID), nil
+}
+
+// Created returns when the membership was added
+func (r *MembershipResolver) Created(ctx context.Context) (graphql.Time, error) {
+	return graphql.Time{Time: r.M.CreatedAt}, nil
+}
@@ -0,0 +1,34 @@
+package gql
+
+import (
+	"context"
+	"go-lambda-graphql/services/auth"
+	"net/http"
+)
+
+// Authenticate validates the access token sent in the Authorization header or auth cookie
+// and stores the caller in the request context for the resolvers
+func Authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if tokenString := auth.TokenFromRequest(r); tokenString != "" {
+			r = r.WithContext(withPrincipal(r.Context(), tokenString))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// withPrincipal authenticates tokenString, the resolvers report the error when the token is not valid
+func withPrincipal(ctx context.Context, tokenString string) context.Context {
+	usr, claims, err := authenticate(tokenString)
+	ctx = auth.WithPrincipal(ctx, &auth.Principal{Claims: claims, Err: err})
+	if err == nil {
+		loadersFromContext(ctx).Users.Prime(usr.ID, usr)
+	}
+	return withViewer(ctx, usr)
+}
+
+// RegistersQueries lets the authenticated callers store new persisted queries
+func RegistersQueries(ctx context.Context) bool {
+	_, _, err := viewer(ctx, nil)
+	return err == nil
+}
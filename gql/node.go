@@ -0,0 +1,151 @@
+package gql
+
+import (
+	"context"
+	"go-lambda-graphql/services/apperr"
+	"strconv"
+	"sync"
+
+	"github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/relay"
+)
+
+// node is implemented by every resolver of a type implementing the Node interface
+type node interface {
+	ID(ctx context.Context) (graphql.ID, error)
+}
+
+// nodeFetcher loads an object from the id spec of its global id, returning nil when it does not exist
+type nodeFetcher func(ctx context.Context, spec ID) (node, error)
+
+var nodeFetchers = map[string]nodeFetcher{}
+
+// registerNode makes objects of kind refetchable through the node and nodes root fields
+func registerNode(kind string, fetch nodeFetcher) {
+	nodeFetchers[kind] = fetch
+}
+
+func init() {
+	registerNode("usr", fetchUserNode)
+}
+
+// NodeResolver resolves the Node interface
+type NodeResolver struct {
+	N node
+}
+
+// fetchNode dispatches a global id to the fetcher registered for its kind
+func fetchNode(ctx context.Context, id graphql.ID) (*NodeResolver, error) {
+	kind := relay.UnmarshalKind(id)
+	fetch, ok := nodeFetchers[kind]
+	if !ok {
+		return nil, apperr.Errorf(apperr.ValidationFailed, "invalid id %s", id)
+	}
+	var spec ID
+	if err := relay.UnmarshalSpec(id, &spec); err != nil {
+		return nil, apperr.Errorf(apperr.ValidationFailed, "invalid id %s", id)
+	}
+	n, err := fetch(ctx, spec)
+	if err != nil || n == nil {
+		return nil, err
+	}
+	return &NodeResolver{N: n}, nil
+}
+
+// fetchUserNode loads a user for the node field, only signed in users can refetch users
+func fetchUserNode(ctx context.Context, spec ID) (node, error) {
+	current, _, err := viewer(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	id, err := strconv.ParseInt(spec.ID, 10, 64)
+	if err != nil {
+		return nil, apperr.New(apperr.ValidationFailed, "invalid user id")
+	}
+	usr, err := loadUser(ctx, id)
+	if err == errUserNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &UserResolver{
+		V: userFromModel(current),
+		U: userFromModel(usr),
+	}, nil
+}
+
+// Node field, an id that does not resolve to an object is null like in the nodes field
+func (r *Resolver) Node(ctx context.Context, args struct {
+	ID graphql.ID
+}) (*NodeResolver, error) {
+	n, err := fetchNode(ctx, args.ID)
+	if unknownNode(err) {
+		return nil, nil
+	}
+	return n, err
+}
+
+// Nodes field, ids that do not resolve to an object are returned as null
+func (r *Resolver) Nodes(ctx context.Context, args struct {
+	IDs []graphql.ID
+}) ([]*NodeResolver, error) {
+	nodes := make([]*NodeResolver, len(args.IDs))
+	errs := make([]error, len(args.IDs))
+	var wg sync.WaitGroup
+	// fetch concurrently so the loaders can batch every id into one query per kind
+	for i, id := range args.IDs {
+		wg.Add(1)
+		go func(i int, id graphql.ID) {
+			defer wg.Done()
+			nodes[i], errs[i] = fetchNode(ctx, id)
+		}(i, id)
+	}
+	wg.Wait()
+	for i, err := range errs {
+		if unknownNode(err) {
+			nodes[i] = nil
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return nodes, nil
+}
+
+// unknownNode reports whether err only means an id does not name an object,
+// a single bad id must not fail the other ids of a nodes list
+func unknownNode(err error) bool {
+	e, ok := err.(*apperr.Error)
+	return ok && (e.Code == apperr.ValidationFailed || e.Code == apperr.NotFound)
+}
+
+// ID returns the id from Node resolver
+func (r *NodeResolver) ID(ctx context.Context) (graphql.ID, error) {
+	return r.N.ID(ctx)
+}
+
+// ToUser resolves the Node as a User
+func (r *NodeResolver) ToUser() (*UserResolver, bool) {
+	u, ok := r.N.(*UserResolver)
+	return u, ok
+}
+
+// ToSubmittalLogFact resolves the Node as a SubmittalLogFact
+func (r *NodeResolver) ToSubmittalLogFact() (*SubmittalLogFactResolver, bool) {
+	s, ok := r.N.(*SubmittalLogFactResolver)
+	return s, ok
+}
+
+// ToCampaign resolves the Node as a Campaign
+func (r *NodeResolver) ToCampaign() (*CampaignResolver, bool) {
+	c, ok := r.N.(*CampaignResolver)
+	return c, ok
+}
+
+// ToItem resolves the Node as an Item
+func (r *NodeResolver) ToItem() (*ItemResolver, bool) {
+	i, ok := r.N.(*ItemResolver)
+	return i, ok
+}
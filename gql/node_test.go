@@ -0,0 +1,89 @@
+package gql
+
+import (
+	"context"
+	"go-lambda-graphql/services/apperr"
+	"testing"
+
+	"github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/relay"
+)
+
+type testNode struct {
+	id graphql.ID
+}
+
+func (n *testNode) ID(ctx context.Context) (graphql.ID, error) {
+	return n.id, nil
+}
+
+func TestNodes(t *testing.T) {
+	registerNode("test", func(ctx context.Context, spec ID) (node, error) {
+		switch spec.ID {
+		case "found":
+			return &testNode{id: relay.MarshalID("test", spec)}, nil
+		case "missing":
+			return nil, nil
+		case "gone":
+			return nil, apperr.New(apperr.NotFound, "gone")
+		}
+		return nil, apperr.New(apperr.Internal, "connection refused")
+	})
+	defer delete(nodeFetchers, "test")
+	found := relay.MarshalID("test", ID{ID: "found"})
+
+	t.Run("unknown and invalid ids are null", func(t *testing.T) {
+		nodes, err := (&Resolver{}).Nodes(context.Background(), struct{ IDs []graphql.ID }{IDs: []graphql.ID{
+			found,
+			relay.MarshalID("test", ID{ID: "missing"}),
+			relay.MarshalID("test", ID{ID: "gone"}),
+			relay.MarshalID("nokind", ID{ID: "found"}),
+			"not a global id",
+		}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(nodes) != 5 || nodes[0] == nil {
+			t.Fatalf("expected the first node, got %v", nodes)
+		}
+		for i, n := range nodes[1:] {
+			if n != nil {
+				t.Errorf("expected id %d to be null", i+1)
+			}
+		}
+	})
+
+	t.Run("unknown and invalid id is null", func(t *testing.T) {
+		for _, id := range []graphql.ID{
+			relay.MarshalID("test", ID{ID: "gone"}),
+			relay.MarshalID("nokind", ID{ID: "found"}),
+			"not a global id",
+		} {
+			n, err := (&Resolver{}).Node(context.Background(), struct{ ID graphql.ID }{ID: id})
+			if n != nil || err != nil {
+				t.Errorf("expected %s to be null, got %v, %v", id, n, err)
+			}
+		}
+		if n, err := (&Resolver{}).Node(context.Background(), struct{ ID graphql.ID }{ID: found}); n == nil || err != nil {
+			t.Errorf("expected the node, got %v", err)
+		}
+	})
+
+	t.Run("register every kind of id the schema mints", func(t *testing.T) {
+		for _, kind := range []string{"usr", "submittal", "campaign", "item"} {
+			if _, ok := nodeFetchers[kind]; !ok {
+				t.Errorf("no fetcher for %s ids", kind)
+			}
+		}
+	})
+
+	t.Run("fail on internal errors", func(t *testing.T) {
+		_, err := (&Resolver{}).Nodes(context.Background(), struct{ IDs []graphql.ID }{IDs: []graphql.ID{
+			found,
+			relay.MarshalID("test", ID{ID: "broken"}),
+		}})
+		if err == nil {
+			t.Errorf("expected an error")
+		}
+	})
+}
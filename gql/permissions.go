@@ -0,0 +1,157 @@
+package gql
+
+import (
+	"context"
+	"go-lambda-graphql/models"
+	"go-lambda-graphql/services/apperr"
+	"go-lambda-graphql/services/cost"
+	"strings"
+
+	"github.com/graph-gophers/graphql-go"
+	"github.com/lib/pq"
+	"github.com/volatiletech/sqlboiler/queries"
+)
+
+// the permissions roles grant, the values of the Permission enum in lower case
+const (
+	permManageRoles           = "manage_roles"
+	permManageMemberships     = "manage_memberships"
+	permManageSubmittals      = "manage_submittals"
+	permReadAllSubmittals     = "read_all_submittals"
+	permReadDeletedSubmittals = "read_deleted_submittals"
+)
+
+// fieldPermissions maps Type.field to the permission required by its @auth directive
+var fieldPermissions = map[string]string{}
+
+// parsedSchema validates the documents the permissions cannot be checked on
+var parsedSchema *graphql.Schema
+
+// MustParseSchema parses the schema against the root resolver and reads its @auth and @cost directives,
+// it panics on error like graphql.MustParseSchema
+func MustParseSchema(raw string) *graphql.Schema {
+	parsedSchema = graphql.MustParseSchema(raw, &Resolver{})
+	costs, permissions, err := schemaDirectives(raw)
+	if err != nil {
+		panic(err)
+	}
+	fieldCosts, fieldPermissions = costs, permissions
+	return parsedSchema
+}
+
+// schemaDirectives reads the fields of the schema in a single pass, with the weight of their @cost directive,
+// whether they take first or last, and the permission of their @auth directive keyed by Type.field
+func schemaDirectives(raw string) (cost.Schema, map[string]string, error) {
+	types, err := cost.ParseSchema(raw)
+	if err != nil {
+		return nil, nil, err
+	}
+	costs := cost.Schema{}
+	permissions := map[string]string{}
+	for typeName, fields := range types {
+		costs[typeName] = map[string]cost.Field{}
+		for name, f := range fields {
+			c := cost.Field{Type: f.Type}
+			for _, arg := range f.Arguments {
+				c.Paginated = c.Paginated || arg == "first" || arg == "last"
+			}
+			if weight, ok := f.Directives["cost"]["weight"].(int64); ok {
+				c.Weight = int(weight)
+			}
+			costs[typeName][name] = c
+			if requires, ok := f.Directives["auth"]["requires"].(string); ok {
+				permissions[typeName+"."+name] = strings.ToLower(requires)
+			}
+		}
+	}
+	return costs, permissions, nil
+}
+
+// authorize enforces the @auth directives of every field the operation selects before it runs,
+// so no resolver can forget to. The permissions are loaded afresh, a subscription connection outlives its loaders
+func authorize(ctx context.Context, document string, operationName string) error {
+	fields, err := cost.Fields(fieldCosts, document, operationName)
+	if err != nil {
+		// a document the schema accepts but the fields or the operation cannot be read from is refused all the same,
+		// graphql-go would run it without its fields being checked
+		if errs := parsedSchema.Validate(document); len(errs) > 0 {
+			return errs[0]
+		}
+		return apperr.New(apperr.ValidationFailed, "the query cannot be authorized")
+	}
+	ctx = context.WithValue(ctx, loadersKey{}, newLoaders())
+	for _, field := range fields {
+		permission, ok := fieldPermissions[field]
+		if !ok {
+			continue
+		}
+		current, _, err := viewer(ctx, nil)
+		if err != nil {
+			return err
+		}
+		granted, err := hasPermission(ctx, current, permission)
+		if err != nil {
+			return err
+		}
+		if !granted {
+			return apperr.Errorf(apperr.Forbidden, "%s requires the %s permission", field, strings.ToUpper(permission))
+		}
+	}
+	return nil
+}
+
+// requirePermission returns the viewer when they hold permission, the resolvers of the most sensitive fields
+// check it again after authorize so the @auth directives are not their only guard
+func requirePermission(ctx context.Context, permission string) (*models.Usr, error) {
+	current, _, err := viewer(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	granted, err := hasPermission(ctx, current, permission)
+	if err != nil {
+		return nil, err
+	}
+	if !granted {
+		return nil, apperr.Errorf(apperr.Forbidden, "the %s permission is required", strings.ToUpper(permission))
+	}
+	return current, nil
+}
+
+// hasPermission reports whether the user holds permission through one of their roles
+func hasPermission(ctx context.Context, usr *models.Usr, permission string) (bool, error) {
+	value, err := loadersFromContext(ctx).Permissions.Load(usr.ID)
+	if err != nil {
+		return false, err
+	}
+	return value.(map[string]bool)[permission], nil
+}
+
+// fetchPermissions loads the permissions of a batch of users in a single query
+func fetchPermissions(ids []int64) ([]interface{}, []error) {
+	values := make([]interface{}, len(ids))
+	errs := make([]error, len(ids))
+	var rows []struct {
+		UsrID      int64  `boil:"usr_id"`
+		Permission string `boil:"permission"`
+	}
+	err := queries.RawG(`select ur.usr_id, rp.permission from usr_role ur
+		join role_permission rp on rp.role = ur.role
+		where ur.usr_id = ANY($1)`, pq.Array(ids)).Bind(&rows)
+	if err != nil {
+		for i := range errs {
+			errs[i] = err
+		}
+		return values, errs
+	}
+	byID := make(map[int64]map[string]bool, len(ids))
+	for _, id := range ids {
+		byID[id] = map[string]bool{}
+	}
+	for _, row := range rows {
+		byID[row.UsrID][row.Permission] = true
+	}
+	for i, id := range ids {
+		values[i] = byID[id]
+	}
+	return values, errs
+}
@@ -0,0 +1,67 @@
+package gql
+
+import (
+	"context"
+	"go-lambda-graphql/services/apperr"
+	"io/ioutil"
+	"testing"
+
+	"github.com/graph-gophers/graphql-go"
+)
+
+func TestAuthDirectives(t *testing.T) {
+	rawSchema, err := ioutil.ReadFile("schema.gql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	MustParseSchema(string(rawSchema))
+	if fieldPermissions["Mutation.grantRole"] != permManageRoles {
+		t.Errorf("unexpected permissions %v", fieldPermissions)
+	}
+	if _, ok := fieldPermissions["Query.viewer"]; ok {
+		t.Errorf("viewer has no @auth directive")
+	}
+
+	t.Run("enforce the directives before running", func(t *testing.T) {
+		for _, q := range []string{
+			`mutation { grantRole(userId: "1", role: "admin") }`,
+			`mutation M { ok: revokeRole(userId: "1", role: "admin") }`,
+			`mutation { ...F } fragment F on Mutation { removeMembership(id: "1") }`,
+			"# x\rmutation { grantRole(userId: \"1\", role: \"admin\") }",
+		} {
+			err := CheckQuery(context.Background(), q, "", nil)
+			if err == nil || err.Extensions["code"] != apperr.Unauthenticated {
+				t.Errorf("expected %s to be refused, got %v", q, err)
+			}
+		}
+	})
+
+	t.Run("let fields without directives through", func(t *testing.T) {
+		if err := CheckQuery(context.Background(), `{ viewer { id } }`, "", nil); err != nil {
+			t.Errorf("unexpected error %v", err)
+		}
+	})
+
+	t.Run("refuse documents that do not parse", func(t *testing.T) {
+		if err := CheckQuery(context.Background(), `mutation { grantRole(`, "", nil); err == nil {
+			t.Errorf("expected an error")
+		}
+	})
+
+	t.Run("refuse documents without the operation", func(t *testing.T) {
+		if err := CheckQuery(context.Background(), `mutation M { grantRole(userId: "1", role: "admin") }`, "N", nil); err == nil {
+			t.Errorf("expected an error")
+		}
+	})
+
+	t.Run("check the permission in the resolver too", func(t *testing.T) {
+		ctx := context.WithValue(context.Background(), loadersKey{}, newLoaders())
+		_, err := (&Resolver{}).GrantRole(ctx, struct {
+			UserID graphql.ID
+			Role   string
+		}{UserID: "1", Role: "admin"})
+		if e, ok := err.(*apperr.Error); !ok || e.Code != apperr.Unauthenticated {
+			t.Errorf("expected an unauthenticated error, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,92 @@
+package gql
+
+import (
+	"context"
+	"go-lambda-graphql/models"
+	"go-lambda-graphql/services/apperr"
+
+	"github.com/graph-gophers/graphql-go"
+	. "github.com/volatiletech/sqlboiler/queries/qm"
+)
+
+// Roles returns the names of the roles of the user, only the user itself and role managers can see them
+func (r *UserResolver) Roles(ctx context.Context) ([]string, error) {
+	current, _, err := viewer(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	id, err := r.U.rowID()
+	if err != nil {
+		return nil, err
+	}
+	if current.ID != id {
+		manager, err := hasPermission(ctx, current, permManageRoles)
+		if err != nil {
+			return nil, err
+		}
+		if !manager {
+			return nil, errForbidden
+		}
+	}
+	roles, err := models.UsrRolesG(Where("usr_id = ?", id), OrderBy("role")).All()
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(roles))
+	for i, role := range roles {
+		names[i] = role.Role
+	}
+	return names, nil
+}
+
+// GrantRole mutation gives a role to a user, granting a role the user has is a no-op
+func (r *Resolver) GrantRole(ctx context.Context, args struct {
+	UserID graphql.ID
+	Role   string
+}) (bool, error) {
+	if _, err := requirePermission(ctx, permManageRoles); err != nil {
+		return false, err
+	}
+	usrID, err := parseUserID(args.UserID)
+	if err != nil {
+		return false, err
+	}
+	known, err := models.RolesG(Where("name = ?", args.Role)).Exists()
+	if err != nil {
+		return false, err
+	}
+	if !known {
+		return false, apperr.Errorf(apperr.ValidationFailed, "unknown role %s", args.Role)
+	}
+	granted, err := models.UsrRolesG(Where("usr_id = ? and role = ?", usrID, args.Role)).Exists()
+	if err != nil || granted {
+		return granted, err
+	}
+	role := &models.UsrRole{UsrID: usrID, Role: args.Role}
+	if err := role.InsertG(); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// RevokeRole mutation takes a role away from a user
+func (r *Resolver) RevokeRole(ctx context.Context, args struct {
+	UserID graphql.ID
+	Role   string
+}) (bool, error) {
+	current, err := requirePermission(ctx, permManageRoles)
+	if err != nil {
+		return false, err
+	}
+	usrID, err := parseUserID(args.UserID)
+	if err != nil {
+		return false, err
+	}
+	if usrID == current.ID && args.Role == "admin" {
+		return false, apperr.New(apperr.Forbidden, "admins cannot revoke their own admin role")
+	}
+	if err := models.UsrRolesG(Where("usr_id = ? and role = ?", usrID, args.Role)).DeleteAll(); err != nil {
+		return false, err
+	}
+	return true, nil
+}
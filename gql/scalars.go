@@ -0,0 +1,130 @@
+package gql
+
+import (
+	"encoding/json"
+	"errors"
+	"go-lambda-graphql/services/submittal"
+	"strconv"
+	"time"
+
+	"github.com/graph-gophers/graphql-go"
+	"github.com/volatiletech/sqlboiler/types"
+	"gopkg.in/volatiletech/null.v6"
+)
+
+const dateFormat = "2006-01-02"
+
+// Date is a calendar date without a time of day, serialized as YYYY-MM-DD
+type Date struct {
+	time.Time
+}
+
+// ImplementsGraphQLType maps Date to the Date scalar
+func (Date) ImplementsGraphQLType(name string) bool {
+	return name == "Date"
+}
+
+// UnmarshalGraphQL parses a Date input
+func (d *Date) UnmarshalGraphQL(input interface{}) error {
+	s, ok := input.(string)
+	if !ok {
+		return errors.New("wrong type for Date, expected YYYY-MM-DD")
+	}
+	t, err := time.Parse(dateFormat, s)
+	if err != nil {
+		return err
+	}
+	d.Time = t
+	return nil
+}
+
+// MarshalJSON serializes the date as YYYY-MM-DD
+func (d Date) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.Format(dateFormat))
+}
+
+// JSON is an arbitrary json value, used for jsonb columns
+type JSON struct {
+	json.RawMessage
+}
+
+// ImplementsGraphQLType maps JSON to the JSON scalar
+func (JSON) ImplementsGraphQLType(name string) bool {
+	return name == "JSON"
+}
+
+// UnmarshalGraphQL keeps a JSON input as raw json
+func (j *JSON) UnmarshalGraphQL(input interface{}) error {
+	raw, err := json.Marshal(input)
+	if err != nil {
+		return err
+	}
+	j.RawMessage = raw
+	return nil
+}
+
+// MarshalJSON writes the raw json as is
+func (j JSON) MarshalJSON() ([]byte, error) {
+	if len(j.RawMessage) == 0 {
+		return []byte("null"), nil
+	}
+	return j.RawMessage, nil
+}
+
+// nullID maps a nullable bigint to an ID, bigints do not fit the 32 bit Int scalar
+func nullID(v null.Int64) *graphql.ID {
+	if !v.Valid {
+		return nil
+	}
+	id := graphql.ID(strconv.FormatInt(v.Int64, 10))
+	return &id
+}
+
+func nullInt(v null.Int) *int32 {
+	if !v.Valid {
+		return nil
+	}
+	i := int32(v.Int)
+	return &i
+}
+
+func nullTime(v null.Time) *graphql.Time {
+	if !v.Valid {
+		return nil
+	}
+	return &graphql.Time{Time: v.Time}
+}
+
+func nullDate(v null.Time) *Date {
+	if !v.Valid {
+		return nil
+	}
+	return &Date{Time: v.Time}
+}
+
+func nullJSON(v null.JSON) *JSON {
+	if !v.Valid {
+		return nil
+	}
+	return &JSON{RawMessage: json.RawMessage(v.JSON)}
+}
+
+// ids maps a bigint[] column to a list of IDs
+func ids(v types.Int64Array) []graphql.ID {
+	l := make([]graphql.ID, len(v))
+	for i, id := range v {
+		l[i] = graphql.ID(strconv.FormatInt(id, 10))
+	}
+	return l
+}
+
+// dates maps a date[] column to a list of Dates, null elements stay null
+func dates(v submittal.Dates) []*Date {
+	l := make([]*Date, len(v))
+	for i, t := range v {
+		if t != nil {
+			l[i] = &Date{Time: *t}
+		}
+	}
+	return l
+}
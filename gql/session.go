@@ -0,0 +1,179 @@
+package gql
+
+import (
+	"context"
+	"database/sql"
+	"go-lambda-graphql/models"
+	"go-lambda-graphql/services/apperr"
+	"go-lambda-graphql/services/auth"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/volatiletech/sqlboiler/boil"
+	. "github.com/volatiletech/sqlboiler/queries/qm"
+)
+
+var errRevokedToken = apperr.New(apperr.Unauthenticated, "token has been revoked")
+
+var errUnauthenticated = apperr.New(apperr.Unauthenticated, "unauthenticated")
+
+var errUserNotFound = apperr.New(apperr.NotFound, "user not found")
+
+var errForbidden = apperr.New(apperr.Forbidden, "forbidden")
+
+var errWrongCredentials = apperr.New(apperr.Unauthenticated, "wrong email or password combination")
+
+var errUnrevocableToken = apperr.New(apperr.Unauthenticated, "token has no jti or exp to revoke")
+
+type viewerKey struct{}
+
+// authenticate validates a jwt, makes sure it was not revoked by a logout or password change
+// and loads the current row of the user it was issued to
+func authenticate(tokenString string) (*models.Usr, jwt.MapClaims, error) {
+	token, err := auth.GetToken(tokenString)
+	if err != nil {
+		return nil, nil, apperr.New(apperr.Unauthenticated, err.Error())
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid {
+		return nil, nil, apperr.New(apperr.Unauthenticated, "invalid token")
+	}
+	usr, err := claimsUser(claims)
+	if err != nil {
+		return nil, nil, err
+	}
+	return usr, claims, nil
+}
+
+// claimsUser checks the claims of a verified token still hold, it has not expired since
+// nor been revoked by a logout or password change, and loads the current row of the user it was issued to
+func claimsUser(claims jwt.MapClaims) (*models.Usr, error) {
+	if err := claims.Valid(); err != nil {
+		return nil, apperr.New(apperr.Unauthenticated, err.Error())
+	}
+	jti, _ := claims["jti"].(string)
+	id, _ := claims["id"].(float64)
+	version, _ := claims["ver"].(float64)
+	revoked, err := models.RevokedTokensG(Where("jti = ?", jti)).Exists()
+	if err != nil {
+		return nil, err
+	}
+	if revoked {
+		return nil, errRevokedToken
+	}
+	usr, err := models.FindUsrG(int64(id))
+	if err == sql.ErrNoRows {
+		return nil, errUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if usr.TokenVersion != int(version) {
+		return nil, errRevokedToken
+	}
+	return usr, nil
+}
+
+// withViewer caches the authenticated user row for the rest of the request
+func withViewer(ctx context.Context, usr *models.Usr) context.Context {
+	return context.WithValue(ctx, viewerKey{}, usr)
+}
+
+// viewer returns the calling user and its token claims, authenticated from the deprecated jwt
+// argument when it is given and from the row the Authenticate middleware cached otherwise
+func viewer(ctx context.Context, tokenString *string) (*models.Usr, jwt.MapClaims, error) {
+	if tokenString != nil {
+		return authenticate(*tokenString)
+	}
+	p := auth.PrincipalFromContext(ctx)
+	if p == nil {
+		return nil, nil, errUnauthenticated
+	}
+	if p.Err != nil {
+		return nil, nil, p.Err
+	}
+	usr, ok := ctx.Value(viewerKey{}).(*models.Usr)
+	if !ok {
+		return nil, nil, errUnauthenticated
+	}
+	return usr, p.Claims, nil
+}
+
+// revokeSessions invalidates every access and refresh token a user holds
+func revokeSessions(exec boil.Executor, usr *models.Usr) error {
+	usr.TokenVersion++
+	if err := usr.Update(exec, "token_version"); err != nil {
+		return err
+	}
+	return models.RefreshTokens(exec, Where("usr_id = ? and revoked_at is null", usr.ID)).UpdateAll(models.M{"revoked_at": time.Now()})
+}
+
+// Logout mutation revokes the given access token and the refresh tokens issued with it
+func (r *Resolver) Logout(ctx context.Context, args struct {
+	Jwt          *string
+	RefreshToken *string
+}) (bool, error) {
+	usr, claims, err := viewer(ctx, args.Jwt)
+	if err != nil {
+		return false, err
+	}
+	jti, ok := claims["jti"].(string)
+	if !ok {
+		return false, errUnrevocableToken
+	}
+	exp, ok := claims["exp"].(float64)
+	if !ok {
+		return false, errUnrevocableToken
+	}
+	tx, err := boil.Begin()
+	if err != nil {
+		return false, err
+	}
+	revoked := models.RevokedToken{
+		Jti:       jti,
+		ExpiresAt: time.Unix(int64(exp), 0),
+	}
+	if err := revoked.Insert(tx); err != nil {
+		tx.Rollback()
+		return false, err
+	}
+	if args.RefreshToken != nil {
+		refreshToken, err := models.RefreshTokens(tx, Where("token_hash = ? and usr_id = ?", hashRefreshToken(*args.RefreshToken), usr.ID)).One()
+		if err == nil {
+			err = models.RefreshTokens(tx, Where("family = ?", refreshToken.Family)).UpdateAll(models.M{"revoked_at": time.Now()})
+		}
+		if err != nil {
+			tx.Rollback()
+			return false, errInvalidRefreshToken
+		}
+	}
+	// expired tokens are rejected on their own, no need to remember them
+	models.RevokedTokens(tx, Where("expires_at < ?", time.Now())).DeleteAll()
+	tx.Commit()
+	return true, nil
+}
+
+// LogoutAllSessions mutation revokes every token the user holds on every device
+func (r *Resolver) LogoutAllSessions(ctx context.Context, args struct {
+	Jwt *string
+}) (bool, error) {
+	current, _, err := viewer(ctx, args.Jwt)
+	if err != nil {
+		return false, err
+	}
+	tx, err := boil.Begin()
+	if err != nil {
+		return false, err
+	}
+	usr, err := models.FindUsr(tx, current.ID)
+	if err != nil {
+		tx.Rollback()
+		return false, err
+	}
+	if err := revokeSessions(tx, usr); err != nil {
+		tx.Rollback()
+		return false, err
+	}
+	tx.Commit()
+	return true, nil
+}
@@ -0,0 +1,19 @@
+package gql
+
+import (
+	"go-lambda-graphql/services/apperr"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+func TestClaimsUser(t *testing.T) {
+	t.Run("reject expired claims", func(t *testing.T) {
+		claims := jwt.MapClaims{"id": 1.0, "jti": "expired", "exp": float64(time.Now().Add(-time.Minute).Unix())}
+		_, err := claimsUser(claims)
+		if e, ok := err.(*apperr.Error); !ok || e.Code != apperr.Unauthenticated {
+			t.Errorf("expected an unauthenticated error, got %v", err)
+		}
+	})
+}
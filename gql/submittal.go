@@ -0,0 +1,697 @@
+package gql
+
+import (
+	"context"
+	"go-lambda-graphql/models"
+	"go-lambda-graphql/services/apperr"
+	"go-lambda-graphql/services/connection"
+	"go-lambda-graphql/services/ingest"
+	"go-lambda-graphql/services/submittal"
+	"strconv"
+	"time"
+
+	"github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/relay"
+	. "github.com/volatiletech/sqlboiler/queries/qm"
+	"gopkg.in/volatiletech/null.v6"
+)
+
+// SubmittalLogFactResolver struct
+type SubmittalLogFactResolver struct {
+	S *submittalRow
+}
+
+// submittalRow is a submittal with its date arrays, sqlboiler reads date[] columns as StringArrays
+// which fail on the null elements of the approvers without a date yet, so they are selected under their own names
+type submittalRow struct {
+	models.SubmittalLogFact `boil:",bind"`
+	SentDates               submittal.Dates `boil:"sent_dates"`
+	ReturnedDates           submittal.Dates `boil:"returned_dates"`
+	DueDates                submittal.Dates `boil:"due_dates"`
+	BallInCourtDueDates     submittal.Dates `boil:"ball_in_court_due_dates"`
+}
+
+// submittalDateArrays maps the date[] columns to the names submittalRow reads them from
+var submittalDateArrays = map[string]string{
+	"approver_sent_dates":     "sent_dates",
+	"approver_returned_dates": "returned_dates",
+	"approver_due_dates":      "due_dates",
+	"ball_in_court_due_date":  "ball_in_court_due_dates",
+}
+
+// submittalColumns selects a submittalRow, the id and the importable columns
+var submittalColumns = func() QueryMod {
+	columns := []string{"submittal_log_facts.id"}
+	for _, c := range ingest.Columns {
+		column := "submittal_log_facts." + c.Name
+		if name, ok := submittalDateArrays[c.Name]; ok {
+			column += " as " + name
+		}
+		columns = append(columns, column)
+	}
+	return Select(columns...)
+}()
+
+// submittalRows loads the submittals matching mods
+func submittalRows(mods ...QueryMod) ([]*submittalRow, error) {
+	var rows []*submittalRow
+	err := models.SubmittalLogFactsG(append([]QueryMod{submittalColumns}, mods...)...).Bind(&rows)
+	return rows, err
+}
+
+// oneSubmittalRow loads the first submittal matching mods, nil when there is none
+func oneSubmittalRow(mods ...QueryMod) (*submittalRow, error) {
+	rows, err := submittalRows(append(mods, Limit(1))...)
+	if err != nil || len(rows) == 0 {
+		return nil, err
+	}
+	return rows[0], nil
+}
+
+// SubmittalLogFactConnectionResolver struct
+type SubmittalLogFactConnectionResolver struct {
+	E     []*SubmittalLogFactEdgeResolver
+	P     connection.PageInfo
+	Total int32
+}
+
+// SubmittalLogFactEdgeResolver struct
+type SubmittalLogFactEdgeResolver struct {
+	C string
+	N *SubmittalLogFactResolver
+}
+
+func init() {
+	registerNode("submittal", fetchSubmittalNode)
+}
+
+// fetchSubmittalNode loads a submittal for the node field
+func fetchSubmittalNode(ctx context.Context, spec ID) (node, error) {
+	current, _, err := viewer(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	id, err := strconv.ParseInt(spec.ID, 10, 64)
+	if err != nil {
+		return nil, apperr.New(apperr.ValidationFailed, "invalid submittal id")
+	}
+	submittal, err := findSubmittal(ctx, current, id)
+	if err != nil || submittal == nil {
+		return nil, err
+	}
+	if submittal.DeletedAt.Valid {
+		deleted, err := hasPermission(ctx, current, permReadDeletedSubmittals)
+		if err != nil || !deleted {
+			return nil, err
+		}
+	}
+	return &SubmittalLogFactResolver{S: submittal}, nil
+}
+
+// DeleteSubmittal mutation soft deletes a submittal, the row stays with its deleted_at set
+func (r *Resolver) DeleteSubmittal(ctx context.Context, args struct {
+	ID graphql.ID
+}) (*SubmittalLogFactResolver, error) {
+	return setSubmittalDeleted(ctx, args.ID, true)
+}
+
+// RestoreSubmittal mutation clears the deleted_at of a soft deleted submittal
+func (r *Resolver) RestoreSubmittal(ctx context.Context, args struct {
+	ID graphql.ID
+}) (*SubmittalLogFactResolver, error) {
+	return setSubmittalDeleted(ctx, args.ID, false)
+}
+
+// setSubmittalDeleted sets or clears deleted_at as a user allowed to manage every submittal or a manager of the project,
+// deleting a deleted submittal keeps its first deleted_at
+func setSubmittalDeleted(ctx context.Context, id graphql.ID, deleted bool) (*SubmittalLogFactResolver, error) {
+	current, _, err := viewer(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	var spec ID
+	if relay.UnmarshalKind(id) != "submittal" || relay.UnmarshalSpec(id, &spec) != nil {
+		return nil, apperr.Errorf(apperr.ValidationFailed, "invalid id %s", id)
+	}
+	rowID, err := strconv.ParseInt(spec.ID, 10, 64)
+	if err != nil {
+		return nil, apperr.New(apperr.ValidationFailed, "invalid submittal id")
+	}
+	submittal, err := findSubmittal(ctx, current, rowID)
+	if err != nil {
+		return nil, err
+	}
+	if submittal == nil {
+		return nil, apperr.New(apperr.NotFound, "submittal not found")
+	}
+	manager, err := canManageSubmittal(ctx, current, submittal)
+	if err != nil {
+		return nil, err
+	}
+	if !manager {
+		return nil, errForbidden
+	}
+	if submittal.DeletedAt.Valid != deleted {
+		now := time.Now()
+		submittal.DeletedAt = null.NewTime(now, deleted)
+		submittal.UpdatedAt = null.TimeFrom(now)
+		if err := submittal.UpdateG("deleted_at", "updated_at"); err != nil {
+			return nil, err
+		}
+	}
+	return &SubmittalLogFactResolver{S: submittal}, nil
+}
+
+// submittalsArgs are the arguments of the submittals field
+type submittalsArgs struct {
+	First          *int32
+	After          *string
+	Last           *int32
+	Before         *string
+	Filter         *submittalLogFilter
+	OrderBy        *submittalLogOrder
+	IncludeDeleted *bool
+}
+
+// Submittals field lists the submittal log facts matching the filter, ordered by id unless orderBy is given
+func (r *Resolver) Submittals(ctx context.Context, args submittalsArgs) (*SubmittalLogFactConnectionResolver, error) {
+	current, _, err := viewer(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	scope, err := submittalScope(ctx, current, args.IncludeDeleted)
+	if err != nil {
+		return nil, err
+	}
+	field, order, err := args.OrderBy.order()
+	if err != nil {
+		return nil, err
+	}
+	page, err := connection.NewPage(connection.Args{
+		First:  args.First,
+		After:  args.After,
+		Last:   args.Last,
+		Before: args.Before,
+	}, order)
+	if err != nil {
+		return nil, err
+	}
+	where, err := args.Filter.compile()
+	if err != nil {
+		return nil, err
+	}
+	return submittalConnection(page, field, append(scope, where.Mods()...))
+}
+
+// submittalConnection runs a page of a submittal query sorted by field, mods filter the whole connection
+func submittalConnection(page *connection.Page, field submittalOrderField, mods []QueryMod) (*SubmittalLogFactConnectionResolver, error) {
+	total, err := models.SubmittalLogFactsG(mods...).Count()
+	if err != nil {
+		return nil, err
+	}
+	submittals, err := submittalRows(append(mods, page.Mods()...)...)
+	if err != nil {
+		return nil, err
+	}
+	var edges []*SubmittalLogFactEdgeResolver
+	var cursors []string
+	for _, i := range page.Indexes(len(submittals)) {
+		submittal := submittals[i]
+		cursor := connection.Cursor{Value: field.value(&submittal.SubmittalLogFact), ID: submittal.ID}.Encode()
+		cursors = append(cursors, cursor)
+		edges = append(edges, &SubmittalLogFactEdgeResolver{
+			C: cursor,
+			N: &SubmittalLogFactResolver{S: submittal},
+		})
+	}
+	return &SubmittalLogFactConnectionResolver{
+		E:     edges,
+		P:     page.PageInfo(cursors),
+		Total: int32(total),
+	}, nil
+}
+
+// Edges returns the edges of the SubmittalLogFact connection
+func (r *SubmittalLogFactConnectionResolver) Edges(ctx context.Context) (*[]*SubmittalLogFactEdgeResolver, error) {
+	return &r.E, nil
+}
+
+// PageInfo returns the page info of the SubmittalLogFact connection
+func (r *SubmittalLogFactConnectionResolver) PageInfo(ctx context.Context) (*PageInfoResolver, error) {
+	return &PageInfoResolver{P: r.P}, nil
+}
+
+// TotalCount returns the number of submittals in the whole connection
+func (r *SubmittalLogFactConnectionResolver) TotalCount(ctx context.Context) (int32, error) {
+	return r.Total, nil
+}
+
+// Cursor returns the cursor of the SubmittalLogFact edge
+func (r *SubmittalLogFactEdgeResolver) Cursor(ctx context.Context) (string, error) {
+	return r.C, nil
+}
+
+// Node returns the submittal of the SubmittalLogFact edge
+func (r *SubmittalLogFactEdgeResolver) Node(ctx context.Context) (*SubmittalLogFactResolver, error) {
+	return r.N, nil
+}
+
+// ID returns the global id of the submittal
+func (r *SubmittalLogFactResolver) ID(ctx context.Context) (graphql.ID, error) {
+	return relay.MarshalID("submittal", ID{strconv.FormatInt(r.S.ID, 10)}), nil
+}
+
+// CompanyID returns the company id of the submittal
+func (r *SubmittalLogFactResolver) CompanyID(ctx context.Context) (*graphql.ID, error) {
+	return nullID(r.S.CompanyID), nil
+}
+
+// CostCodeID returns the cost code id of the submittal
+func (r *SubmittalLogFactResolver) CostCodeID(ctx context.Context) (*graphql.ID, error) {
+	return nullID(r.S.CostCodeID), nil
+}
+
+// CreatedByID returns the created by id of the submittal
+func (r *SubmittalLogFactResolver) CreatedByID(ctx context.Context) (*graphql.ID, error) {
+	return nullID(r.S.CreatedByID), nil
+}
+
+// LocationID returns the location id of the submittal
+func (r *SubmittalLogFactResolver) LocationID(ctx context.Context) (*graphql.ID, error) {
+	return nullID(r.S.LocationID), nil
+}
+
+// ProjectID returns the project id of the submittal
+func (r *SubmittalLogFactResolver) ProjectID(ctx context.Context) (*graphql.ID, error) {
+	return nullID(r.S.ProjectID), nil
+}
+
+// ReceivedFromID returns the received from id of the submittal
+func (r *SubmittalLogFactResolver) ReceivedFromID(ctx context.Context) (*graphql.ID, error) {
+	return nullID(r.S.ReceivedFromID), nil
+}
+
+// ResponsibleContractorID returns the responsible contractor id of the submittal
+func (r *SubmittalLogFactResolver) ResponsibleContractorID(ctx context.Context) (*graphql.ID, error) {
+	return nullID(r.S.ResponsibleContractorID), nil
+}
+
+// SpecificationSectionID returns the specification section id of the submittal
+func (r *SubmittalLogFactResolver) SpecificationSectionID(ctx context.Context) (*graphql.ID, error) {
+	return nullID(r.S.SpecificationSectionID), nil
+}
+
+// SubmittalLogID returns the submittal log id of the submittal
+func (r *SubmittalLogFactResolver) SubmittalLogID(ctx context.Context) (*graphql.ID, error) {
+	return nullID(r.S.SubmittalLogID), nil
+}
+
+// SubmittalLogStatusID returns the submittal log status id of the submittal
+func (r *SubmittalLogFactResolver) SubmittalLogStatusID(ctx context.Context) (*graphql.ID, error) {
+	return nullID(r.S.SubmittalLogStatusID), nil
+}
+
+// SubmittalManagerID returns the submittal manager id of the submittal
+func (r *SubmittalLogFactResolver) SubmittalManagerID(ctx context.Context) (*graphql.ID, error) {
+	return nullID(r.S.SubmittalManagerID), nil
+}
+
+// SubmittalPackageID returns the submittal package id of the submittal
+func (r *SubmittalLogFactResolver) SubmittalPackageID(ctx context.Context) (*graphql.ID, error) {
+	return nullID(r.S.SubmittalPackageID), nil
+}
+
+// CostCode returns the cost code of the submittal
+func (r *SubmittalLogFactResolver) CostCode(ctx context.Context) (*string, error) {
+	return r.S.CostCode.Ptr(), nil
+}
+
+// CreatedBy returns the created by of the submittal
+func (r *SubmittalLogFactResolver) CreatedBy(ctx context.Context) (*string, error) {
+	return r.S.CreatedBy.Ptr(), nil
+}
+
+// CustomTextarea1 returns the custom textarea 1 of the submittal
+func (r *SubmittalLogFactResolver) CustomTextarea1(ctx context.Context) (*string, error) {
+	return r.S.CustomTextarea1.Ptr(), nil
+}
+
+// CustomTextfield1 returns the custom textfield 1 of the submittal
+func (r *SubmittalLogFactResolver) CustomTextfield1(ctx context.Context) (*string, error) {
+	return r.S.CustomTextfield1.Ptr(), nil
+}
+
+// DeletedAt returns the deleted at of the submittal
+func (r *SubmittalLogFactResolver) DeletedAt(ctx context.Context) (*graphql.Time, error) {
+	return nullTime(r.S.DeletedAt), nil
+}
+
+// DateReceived returns the date received of the submittal
+func (r *SubmittalLogFactResolver) DateReceived(ctx context.Context) (*Date, error) {
+	return nullDate(r.S.DateReceived), nil
+}
+
+// DateRequiredOnSite returns the date required on site of the submittal
+func (r *SubmittalLogFactResolver) DateRequiredOnSite(ctx context.Context) (*Date, error) {
+	return nullDate(r.S.DateRequiredOnSite), nil
+}
+
+// DateIssue returns the date issue of the submittal
+func (r *SubmittalLogFactResolver) DateIssue(ctx context.Context) (*Date, error) {
+	return nullDate(r.S.DateIssue), nil
+}
+
+// DateCreatedAt returns the date created at of the submittal
+func (r *SubmittalLogFactResolver) DateCreatedAt(ctx context.Context) (*Date, error) {
+	return nullDate(r.S.DateCreatedAt), nil
+}
+
+// DateSubmitBy returns the date submit by of the submittal
+func (r *SubmittalLogFactResolver) DateSubmitBy(ctx context.Context) (*Date, error) {
+	return nullDate(r.S.DateSubmitBy), nil
+}
+
+// DateDistributed returns the date distributed of the submittal
+func (r *SubmittalLogFactResolver) DateDistributed(ctx context.Context) (*Date, error) {
+	return nullDate(r.S.DateDistributed), nil
+}
+
+// DateFinalDue returns the date final due of the submittal
+func (r *SubmittalLogFactResolver) DateFinalDue(ctx context.Context) (*Date, error) {
+	return nullDate(r.S.DateFinalDue), nil
+}
+
+// DateActualDelivery returns the date actual delivery of the submittal
+func (r *SubmittalLogFactResolver) DateActualDelivery(ctx context.Context) (*Date, error) {
+	return nullDate(r.S.DateActualDelivery), nil
+}
+
+// DateConfirmedDelivery returns the date confirmed delivery of the submittal
+func (r *SubmittalLogFactResolver) DateConfirmedDelivery(ctx context.Context) (*Date, error) {
+	return nullDate(r.S.DateConfirmedDelivery), nil
+}
+
+// DateAnticipatedDelivery returns the date anticipated delivery of the submittal
+func (r *SubmittalLogFactResolver) DateAnticipatedDelivery(ctx context.Context) (*Date, error) {
+	return nullDate(r.S.DateAnticipatedDelivery), nil
+}
+
+// Description returns the description of the submittal
+func (r *SubmittalLogFactResolver) Description(ctx context.Context) (*string, error) {
+	return r.S.Description.Ptr(), nil
+}
+
+// DesignTeamReviewTime returns the design team review time of the submittal
+func (r *SubmittalLogFactResolver) DesignTeamReviewTime(ctx context.Context) (*int32, error) {
+	return nullInt(r.S.DesignTeamReviewTime), nil
+}
+
+// InternalReviewTime returns the internal review time of the submittal
+func (r *SubmittalLogFactResolver) InternalReviewTime(ctx context.Context) (*int32, error) {
+	return nullInt(r.S.InternalReviewTime), nil
+}
+
+// LeadTime returns the lead time of the submittal
+func (r *SubmittalLogFactResolver) LeadTime(ctx context.Context) (*int32, error) {
+	return nullInt(r.S.LeadTime), nil
+}
+
+// Location returns the location of the submittal
+func (r *SubmittalLogFactResolver) Location(ctx context.Context) (*string, error) {
+	return r.S.Location.Ptr(), nil
+}
+
+// Number returns the number of the submittal
+func (r *SubmittalLogFactResolver) Number(ctx context.Context) (*string, error) {
+	return r.S.Number.Ptr(), nil
+}
+
+// PackageName returns the package name of the submittal
+func (r *SubmittalLogFactResolver) PackageName(ctx context.Context) (*string, error) {
+	return r.S.PackageName.Ptr(), nil
+}
+
+// PackageNumber returns the package number of the submittal
+func (r *SubmittalLogFactResolver) PackageNumber(ctx context.Context) (*string, error) {
+	return r.S.PackageNumber.Ptr(), nil
+}
+
+// PlannedInternalReviewCompletedDate returns the planned internal review completed date of the submittal
+func (r *SubmittalLogFactResolver) PlannedInternalReviewCompletedDate(ctx context.Context) (*Date, error) {
+	return nullDate(r.S.PlannedInternalReviewCompletedDate), nil
+}
+
+// PlannedReturnDate returns the planned return date of the submittal
+func (r *SubmittalLogFactResolver) PlannedReturnDate(ctx context.Context) (*Date, error) {
+	return nullDate(r.S.PlannedReturnDate), nil
+}
+
+// PlannedSubmitByDate returns the planned submit by date of the submittal
+func (r *SubmittalLogFactResolver) PlannedSubmitByDate(ctx context.Context) (*Date, error) {
+	return nullDate(r.S.PlannedSubmitByDate), nil
+}
+
+// ProjectAddress returns the project address of the submittal
+func (r *SubmittalLogFactResolver) ProjectAddress(ctx context.Context) (*string, error) {
+	return r.S.ProjectAddress.Ptr(), nil
+}
+
+// ProjectBidType returns the project bid type of the submittal
+func (r *SubmittalLogFactResolver) ProjectBidType(ctx context.Context) (*string, error) {
+	return r.S.ProjectBidType.Ptr(), nil
+}
+
+// ProjectCity returns the project city of the submittal
+func (r *SubmittalLogFactResolver) ProjectCity(ctx context.Context) (*string, error) {
+	return r.S.ProjectCity.Ptr(), nil
+}
+
+// ProjectCounty returns the project county of the submittal
+func (r *SubmittalLogFactResolver) ProjectCounty(ctx context.Context) (*string, error) {
+	return r.S.ProjectCounty.Ptr(), nil
+}
+
+// ProjectDateCreated returns the project date created of the submittal
+func (r *SubmittalLogFactResolver) ProjectDateCreated(ctx context.Context) (*Date, error) {
+	return nullDate(r.S.ProjectDateCreated), nil
+}
+
+// ProjectDepartment returns the project department of the submittal
+func (r *SubmittalLogFactResolver) ProjectDepartment(ctx context.Context) (*JSON, error) {
+	return nullJSON(r.S.ProjectDepartment), nil
+}
+
+// ProjectEstimatedStartDate returns the project estimated start date of the submittal
+func (r *SubmittalLogFactResolver) ProjectEstimatedStartDate(ctx context.Context) (*Date, error) {
+	return nullDate(r.S.ProjectEstimatedStartDate), nil
+}
+
+// ProjectEstimatedCompletionDate returns the project estimated completion date of the submittal
+func (r *SubmittalLogFactResolver) ProjectEstimatedCompletionDate(ctx context.Context) (*Date, error) {
+	return nullDate(r.S.ProjectEstimatedCompletionDate), nil
+}
+
+// ProjectDescription returns the project description of the submittal
+func (r *SubmittalLogFactResolver) ProjectDescription(ctx context.Context) (*string, error) {
+	return r.S.ProjectDescription.Ptr(), nil
+}
+
+// ProjectDesignatedMarketArea returns the project designated market area of the submittal
+func (r *SubmittalLogFactResolver) ProjectDesignatedMarketArea(ctx context.Context) (*string, error) {
+	return r.S.ProjectDesignatedMarketArea.Ptr(), nil
+}
+
+// ProjectName returns the project name of the submittal
+func (r *SubmittalLogFactResolver) ProjectName(ctx context.Context) (*string, error) {
+	return r.S.ProjectName.Ptr(), nil
+}
+
+// ProjectNotes returns the project notes of the submittal
+func (r *SubmittalLogFactResolver) ProjectNotes(ctx context.Context) (*string, error) {
+	return r.S.ProjectNotes.Ptr(), nil
+}
+
+// ProjectNumber returns the project number of the submittal
+func (r *SubmittalLogFactResolver) ProjectNumber(ctx context.Context) (*string, error) {
+	return r.S.ProjectNumber.Ptr(), nil
+}
+
+// ProjectOffice returns the project office of the submittal
+func (r *SubmittalLogFactResolver) ProjectOffice(ctx context.Context) (*string, error) {
+	return r.S.ProjectOffice.Ptr(), nil
+}
+
+// ProjectOwnerType returns the project owner type of the submittal
+func (r *SubmittalLogFactResolver) ProjectOwnerType(ctx context.Context) (*string, error) {
+	return r.S.ProjectOwnerType.Ptr(), nil
+}
+
+// ProjectParentJob returns the project parent job of the submittal
+func (r *SubmittalLogFactResolver) ProjectParentJob(ctx context.Context) (*string, error) {
+	return r.S.ProjectParentJob.Ptr(), nil
+}
+
+// ProjectPhone returns the project phone of the submittal
+func (r *SubmittalLogFactResolver) ProjectPhone(ctx context.Context) (*string, error) {
+	return r.S.ProjectPhone.Ptr(), nil
+}
+
+// ProjectProgram returns the project program of the submittal
+func (r *SubmittalLogFactResolver) ProjectProgram(ctx context.Context) (*string, error) {
+	return r.S.ProjectProgram.Ptr(), nil
+}
+
+// ProjectSquareFeet returns the project square feet of the submittal
+func (r *SubmittalLogFactResolver) ProjectSquareFeet(ctx context.Context) (*int32, error) {
+	return nullInt(r.S.ProjectSquareFeet), nil
+}
+
+// ProjectRegion returns the project region of the submittal
+func (r *SubmittalLogFactResolver) ProjectRegion(ctx context.Context) (*string, error) {
+	return r.S.ProjectRegion.Ptr(), nil
+}
+
+// ProjectStage returns the project stage of the submittal
+func (r *SubmittalLogFactResolver) ProjectStage(ctx context.Context) (*string, error) {
+	return r.S.ProjectStage.Ptr(), nil
+}
+
+// ProjectState returns the project state of the submittal
+func (r *SubmittalLogFactResolver) ProjectState(ctx context.Context) (*string, error) {
+	return r.S.ProjectState.Ptr(), nil
+}
+
+// ProjectType returns the project type of the submittal
+func (r *SubmittalLogFactResolver) ProjectType(ctx context.Context) (*string, error) {
+	return r.S.ProjectType.Ptr(), nil
+}
+
+// ProjectZip returns the project zip of the submittal
+func (r *SubmittalLogFactResolver) ProjectZip(ctx context.Context) (*string, error) {
+	return r.S.ProjectZip.Ptr(), nil
+}
+
+// Private returns the private of the submittal
+func (r *SubmittalLogFactResolver) Private(ctx context.Context) (*bool, error) {
+	return r.S.Private.Ptr(), nil
+}
+
+// ReceivedFrom returns the received from of the submittal
+func (r *SubmittalLogFactResolver) ReceivedFrom(ctx context.Context) (*string, error) {
+	return r.S.ReceivedFrom.Ptr(), nil
+}
+
+// ResponsibleContractor returns the responsible contractor of the submittal
+func (r *SubmittalLogFactResolver) ResponsibleContractor(ctx context.Context) (*string, error) {
+	return r.S.ResponsibleContractor.Ptr(), nil
+}
+
+// Revision returns the revision of the submittal
+func (r *SubmittalLogFactResolver) Revision(ctx context.Context) (*string, error) {
+	return r.S.Revision.Ptr(), nil
+}
+
+// ScheduledTask returns the scheduled task of the submittal
+func (r *SubmittalLogFactResolver) ScheduledTask(ctx context.Context) (*string, error) {
+	return r.S.ScheduledTask.Ptr(), nil
+}
+
+// SpecSectionDescription returns the spec section description of the submittal
+func (r *SubmittalLogFactResolver) SpecSectionDescription(ctx context.Context) (*string, error) {
+	return r.S.SpecSectionDescription.Ptr(), nil
+}
+
+// SpecSectionNumber returns the spec section number of the submittal
+func (r *SubmittalLogFactResolver) SpecSectionNumber(ctx context.Context) (*string, error) {
+	return r.S.SpecSectionNumber.Ptr(), nil
+}
+
+// Status returns the status of the submittal
+func (r *SubmittalLogFactResolver) Status(ctx context.Context) (*string, error) {
+	return r.S.Status.Ptr(), nil
+}
+
+// StatusName returns the status name of the submittal
+func (r *SubmittalLogFactResolver) StatusName(ctx context.Context) (*string, error) {
+	return r.S.StatusName.Ptr(), nil
+}
+
+// SubmittalManager returns the submittal manager of the submittal
+func (r *SubmittalLogFactResolver) SubmittalManager(ctx context.Context) (*string, error) {
+	return r.S.SubmittalManager.Ptr(), nil
+}
+
+// SubmittalType returns the submittal type of the submittal
+func (r *SubmittalLogFactResolver) SubmittalType(ctx context.Context) (*string, error) {
+	return r.S.SubmittalType.Ptr(), nil
+}
+
+// Title returns the title of the submittal
+func (r *SubmittalLogFactResolver) Title(ctx context.Context) (*string, error) {
+	return r.S.Title.Ptr(), nil
+}
+
+// CreatedAt returns the created at of the submittal
+func (r *SubmittalLogFactResolver) CreatedAt(ctx context.Context) (*graphql.Time, error) {
+	return nullTime(r.S.CreatedAt), nil
+}
+
+// UpdatedAt returns the updated at of the submittal
+func (r *SubmittalLogFactResolver) UpdatedAt(ctx context.Context) (*graphql.Time, error) {
+	return nullTime(r.S.UpdatedAt), nil
+}
+
+// ApproverNames returns the approver names of the submittal
+func (r *SubmittalLogFactResolver) ApproverNames(ctx context.Context) ([]string, error) {
+	return []string(r.S.ApproverNames), nil
+}
+
+// ApproverIDs returns the approver ids of the submittal
+func (r *SubmittalLogFactResolver) ApproverIDs(ctx context.Context) ([]graphql.ID, error) {
+	return ids(r.S.ApproverIDs), nil
+}
+
+// ApproverVendorIDs returns the approver vendor ids of the submittal
+func (r *SubmittalLogFactResolver) ApproverVendorIDs(ctx context.Context) ([]graphql.ID, error) {
+	return ids(r.S.ApproverVendorIDs), nil
+}
+
+// ApproverResponses returns the approver responses of the submittal
+func (r *SubmittalLogFactResolver) ApproverResponses(ctx context.Context) ([]string, error) {
+	return []string(r.S.ApproverResponses), nil
+}
+
+// ApproverSentDates returns the approver sent dates of the submittal
+func (r *SubmittalLogFactResolver) ApproverSentDates(ctx context.Context) ([]*Date, error) {
+	return dates(r.S.SentDates), nil
+}
+
+// ApproverReturnedDates returns the approver returned dates of the submittal
+func (r *SubmittalLogFactResolver) ApproverReturnedDates(ctx context.Context) ([]*Date, error) {
+	return dates(r.S.ReturnedDates), nil
+}
+
+// ApproverDueDates returns the approver due dates of the submittal
+func (r *SubmittalLogFactResolver) ApproverDueDates(ctx context.Context) ([]*Date, error) {
+	return dates(r.S.DueDates), nil
+}
+
+// ResponsedVendorIDs returns the responsed vendor ids of the submittal
+func (r *SubmittalLogFactResolver) ResponsedVendorIDs(ctx context.Context) ([]graphql.ID, error) {
+	return ids(r.S.ResponsedVendorIDs), nil
+}
+
+// BallInCourtNames returns the ball in court names of the submittal
+func (r *SubmittalLogFactResolver) BallInCourtNames(ctx context.Context) ([]string, error) {
+	return []string(r.S.BallInCourtNames), nil
+}
+
+// BallInCourtIDs returns the ball in court ids of the submittal
+func (r *SubmittalLogFactResolver) BallInCourtIDs(ctx context.Context) ([]graphql.ID, error) {
+	return ids(r.S.BallInCourtIDs), nil
+}
+
+// BallInCourtDueDate returns the ball in court due date of the submittal
+func (r *SubmittalLogFactResolver) BallInCourtDueDate(ctx context.Context) ([]*Date, error) {
+	return dates(r.S.BallInCourtDueDates), nil
+}
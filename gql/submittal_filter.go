@@ -0,0 +1,413 @@
+package gql
+
+import (
+	"go-lambda-graphql/models"
+	"go-lambda-graphql/services/apperr"
+	"go-lambda-graphql/services/connection"
+	"go-lambda-graphql/services/filter"
+	"go-lambda-graphql/services/submittal"
+	"strconv"
+	"time"
+
+	"github.com/graph-gophers/graphql-go"
+	"gopkg.in/volatiletech/null.v6"
+)
+
+// submittalFilterColumns whitelists the submittal_log_facts columns SubmittalLogFilter can reach
+var submittalFilterColumns = filter.Columns{
+	"project_id":                filter.Scalar,
+	"company_id":                filter.Scalar,
+	"location_id":               filter.Scalar,
+	"responsible_contractor_id": filter.Scalar,
+	"submittal_manager_id":      filter.Scalar,
+	"submittal_log_status_id":   filter.Scalar,
+	"submittal_package_id":      filter.Scalar,
+	"lead_time":                 filter.Scalar,
+	"date_final_due":            filter.Scalar,
+	"planned_return_date":       filter.Scalar,
+	"planned_submit_by_date":    filter.Scalar,
+	"date_received":             filter.Scalar,
+	"date_issue":                filter.Scalar,
+	"date_required_on_site":     filter.Scalar,
+	"status":                    filter.Text,
+	"status_name":               filter.Text,
+	"submittal_manager":         filter.Text,
+	"responsible_contractor":    filter.Text,
+	"title":                     filter.Text,
+	"number":                    filter.Text,
+	"spec_section_number":       filter.Text,
+	"submittal_type":            filter.Text,
+	"ball_in_court_ids":         filter.Array,
+	"approver_ids":              filter.Array,
+}
+
+// idFilter is the IDFilter input
+type idFilter struct {
+	Eq *graphql.ID
+	In *[]graphql.ID
+}
+
+// stringFilter is the StringFilter input
+type stringFilter struct {
+	Eq       *string
+	In       *[]string
+	Contains *string
+}
+
+// intFilter is the IntFilter input
+type intFilter struct {
+	Eq    *int32
+	In    *[]int32
+	Range *struct {
+		From *int32
+		To   *int32
+	}
+}
+
+// dateFilter is the DateFilter input
+type dateFilter struct {
+	Eq    *Date
+	Range *struct {
+		From *Date
+		To   *Date
+	}
+}
+
+// idArrayFilter is the IDArrayFilter input
+type idArrayFilter struct {
+	Contains *[]graphql.ID
+	Overlaps *[]graphql.ID
+}
+
+// submittalLogFilter is the SubmittalLogFilter input, every operator set on it must match
+type submittalLogFilter struct {
+	And                     *[]*submittalLogFilter
+	Or                      *[]*submittalLogFilter
+	ProjectID               *idFilter
+	CompanyID               *idFilter
+	LocationID              *idFilter
+	ResponsibleContractorID *idFilter
+	SubmittalManagerID      *idFilter
+	SubmittalLogStatusID    *idFilter
+	SubmittalPackageID      *idFilter
+	LeadTime                *intFilter
+	DateFinalDue            *dateFilter
+	PlannedReturnDate       *dateFilter
+	PlannedSubmitByDate     *dateFilter
+	DateReceived            *dateFilter
+	DateIssue               *dateFilter
+	DateRequiredOnSite      *dateFilter
+	Status                  *stringFilter
+	StatusName              *stringFilter
+	SubmittalManager        *stringFilter
+	ResponsibleContractor   *stringFilter
+	Title                   *stringFilter
+	Number                  *stringFilter
+	SpecSectionNumber       *stringFilter
+	SubmittalType           *stringFilter
+	BallInCourtIDs          *idArrayFilter
+	ApproverIDs             *idArrayFilter
+	OverdueFor              *graphql.ID
+}
+
+// compile turns the filter into a clause over submittal_log_facts
+func (f *submittalLogFilter) compile() (filter.Clause, error) {
+	b := filter.New(submittalFilterColumns)
+	c, err := f.clause(b)
+	if err != nil {
+		return c, err
+	}
+	return c, b.Err()
+}
+
+func (f *submittalLogFilter) clause(b *filter.Builder) (filter.Clause, error) {
+	if f == nil {
+		return filter.Clause{}, nil
+	}
+	var clauses []filter.Clause
+	for _, op := range []struct {
+		column string
+		filter *idFilter
+	}{
+		{"project_id", f.ProjectID},
+		{"company_id", f.CompanyID},
+		{"location_id", f.LocationID},
+		{"responsible_contractor_id", f.ResponsibleContractorID},
+		{"submittal_manager_id", f.SubmittalManagerID},
+		{"submittal_log_status_id", f.SubmittalLogStatusID},
+		{"submittal_package_id", f.SubmittalPackageID},
+	} {
+		c, err := op.filter.clause(b, op.column)
+		if err != nil {
+			return c, err
+		}
+		clauses = append(clauses, c)
+	}
+	for _, op := range []struct {
+		column string
+		filter *stringFilter
+	}{
+		{"status", f.Status},
+		{"status_name", f.StatusName},
+		{"submittal_manager", f.SubmittalManager},
+		{"responsible_contractor", f.ResponsibleContractor},
+		{"title", f.Title},
+		{"number", f.Number},
+		{"spec_section_number", f.SpecSectionNumber},
+		{"submittal_type", f.SubmittalType},
+	} {
+		clauses = append(clauses, op.filter.clause(b, op.column))
+	}
+	for _, op := range []struct {
+		column string
+		filter *dateFilter
+	}{
+		{"date_final_due", f.DateFinalDue},
+		{"planned_return_date", f.PlannedReturnDate},
+		{"planned_submit_by_date", f.PlannedSubmitByDate},
+		{"date_received", f.DateReceived},
+		{"date_issue", f.DateIssue},
+		{"date_required_on_site", f.DateRequiredOnSite},
+	} {
+		clauses = append(clauses, op.filter.clause(b, op.column))
+	}
+	for _, op := range []struct {
+		column string
+		filter *idArrayFilter
+	}{
+		{"ball_in_court_ids", f.BallInCourtIDs},
+		{"approver_ids", f.ApproverIDs},
+	} {
+		c, err := op.filter.clause(b, op.column)
+		if err != nil {
+			return c, err
+		}
+		clauses = append(clauses, c)
+	}
+	clauses = append(clauses, f.LeadTime.clause(b, "lead_time"))
+	if f.OverdueFor != nil {
+		id, err := parseID(*f.OverdueFor)
+		if err != nil {
+			return filter.Clause{}, err
+		}
+		clauses = append(clauses, filter.Clause{SQL: submittal.OverdueForSQL, Args: []interface{}{id, submittal.Today().Format(submittal.DateFormat)}})
+	}
+	if f.And != nil {
+		for _, sub := range *f.And {
+			c, err := sub.clause(b)
+			if err != nil {
+				return c, err
+			}
+			clauses = append(clauses, c)
+		}
+	}
+	if f.Or != nil && len(*f.Or) > 0 {
+		var ors []filter.Clause
+		for _, sub := range *f.Or {
+			c, err := sub.clause(b)
+			if err != nil {
+				return c, err
+			}
+			ors = append(ors, c)
+		}
+		clauses = append(clauses, filter.Or(ors...))
+	}
+	return filter.And(clauses...), nil
+}
+
+func (f *idFilter) clause(b *filter.Builder, column string) (filter.Clause, error) {
+	if f == nil {
+		return filter.Clause{}, nil
+	}
+	var clauses []filter.Clause
+	if f.Eq != nil {
+		id, err := parseID(*f.Eq)
+		if err != nil {
+			return filter.Clause{}, err
+		}
+		clauses = append(clauses, b.Eq(column, id))
+	}
+	if f.In != nil {
+		ids, err := parseIDs(*f.In)
+		if err != nil {
+			return filter.Clause{}, err
+		}
+		clauses = append(clauses, b.In(column, ids))
+	}
+	return filter.And(clauses...), nil
+}
+
+func (f *stringFilter) clause(b *filter.Builder, column string) filter.Clause {
+	if f == nil {
+		return filter.Clause{}
+	}
+	var clauses []filter.Clause
+	if f.Eq != nil {
+		clauses = append(clauses, b.Eq(column, *f.Eq))
+	}
+	if f.In != nil {
+		clauses = append(clauses, b.In(column, *f.In))
+	}
+	if f.Contains != nil {
+		clauses = append(clauses, b.Contains(column, *f.Contains))
+	}
+	return filter.And(clauses...)
+}
+
+func (f *intFilter) clause(b *filter.Builder, column string) filter.Clause {
+	if f == nil {
+		return filter.Clause{}
+	}
+	var clauses []filter.Clause
+	if f.Eq != nil {
+		clauses = append(clauses, b.Eq(column, int64(*f.Eq)))
+	}
+	if f.In != nil {
+		in := make([]int64, len(*f.In))
+		for i, v := range *f.In {
+			in[i] = int64(v)
+		}
+		clauses = append(clauses, b.In(column, in))
+	}
+	if f.Range != nil {
+		var from, to interface{}
+		if f.Range.From != nil {
+			from = int64(*f.Range.From)
+		}
+		if f.Range.To != nil {
+			to = int64(*f.Range.To)
+		}
+		clauses = append(clauses, b.Range(column, from, to))
+	}
+	return filter.And(clauses...)
+}
+
+func (f *dateFilter) clause(b *filter.Builder, column string) filter.Clause {
+	if f == nil {
+		return filter.Clause{}
+	}
+	var clauses []filter.Clause
+	if f.Eq != nil {
+		clauses = append(clauses, b.Eq(column, f.Eq.Format(dateFormat)))
+	}
+	if f.Range != nil {
+		// dates are sent as YYYY-MM-DD strings so postgres compares them as dates, not timestamps
+		var from, to interface{}
+		if f.Range.From != nil {
+			from = f.Range.From.Format(dateFormat)
+		}
+		if f.Range.To != nil {
+			to = f.Range.To.Format(dateFormat)
+		}
+		clauses = append(clauses, b.Range(column, from, to))
+	}
+	return filter.And(clauses...)
+}
+
+func (f *idArrayFilter) clause(b *filter.Builder, column string) (filter.Clause, error) {
+	if f == nil {
+		return filter.Clause{}, nil
+	}
+	var clauses []filter.Clause
+	if f.Contains != nil {
+		ids, err := parseIDs(*f.Contains)
+		if err != nil {
+			return filter.Clause{}, err
+		}
+		clauses = append(clauses, b.ArrayContains(column, ids))
+	}
+	if f.Overlaps != nil {
+		ids, err := parseIDs(*f.Overlaps)
+		if err != nil {
+			return filter.Clause{}, err
+		}
+		clauses = append(clauses, b.Overlaps(column, ids))
+	}
+	return filter.And(clauses...), nil
+}
+
+// parseID reads a raw bigint id, as returned by the foreign key fields
+func parseID(id graphql.ID) (int64, error) {
+	i, err := strconv.ParseInt(string(id), 10, 64)
+	if err != nil {
+		return 0, apperr.Errorf(apperr.ValidationFailed, "invalid id %s", id)
+	}
+	return i, nil
+}
+
+func parseIDs(ids []graphql.ID) ([]int64, error) {
+	l := make([]int64, len(ids))
+	for i, id := range ids {
+		v, err := parseID(id)
+		if err != nil {
+			return nil, err
+		}
+		l[i] = v
+	}
+	return l, nil
+}
+
+// submittalLogOrder is the SubmittalLogOrder input
+type submittalLogOrder struct {
+	Field     string
+	Direction *string
+}
+
+// submittalOrderField is a sortable column, value reads the cursor value of a row,
+// nullable columns are coalesced so the keyset comparison never meets a null
+type submittalOrderField struct {
+	column string
+	value  func(s *models.SubmittalLogFact) interface{}
+}
+
+var submittalOrderFields = map[string]submittalOrderField{
+	"ID": {"id", func(s *models.SubmittalLogFact) interface{} {
+		return s.ID
+	}},
+	"DATE_FINAL_DUE": {"coalesce(date_final_due, '-infinity')", func(s *models.SubmittalLogFact) interface{} {
+		return dateCursor(s.DateFinalDue)
+	}},
+	"PLANNED_RETURN_DATE": {"coalesce(planned_return_date, '-infinity')", func(s *models.SubmittalLogFact) interface{} {
+		return dateCursor(s.PlannedReturnDate)
+	}},
+	"DATE_RECEIVED": {"coalesce(date_received, '-infinity')", func(s *models.SubmittalLogFact) interface{} {
+		return dateCursor(s.DateReceived)
+	}},
+	"NUMBER": {"coalesce(number, '')", func(s *models.SubmittalLogFact) interface{} {
+		return s.Number.String
+	}},
+	"TITLE": {"coalesce(title, '')", func(s *models.SubmittalLogFact) interface{} {
+		return s.Title.String
+	}},
+	"STATUS": {"coalesce(status, '')", func(s *models.SubmittalLogFact) interface{} {
+		return s.Status.String
+	}},
+	"UPDATED_AT": {"coalesce(updated_at, '-infinity')", func(s *models.SubmittalLogFact) interface{} {
+		if !s.UpdatedAt.Valid {
+			return "-infinity"
+		}
+		return s.UpdatedAt.Time.Format(time.RFC3339Nano)
+	}},
+}
+
+// dateCursor formats a date column the way postgres reads it back
+func dateCursor(v null.Time) string {
+	if !v.Valid {
+		return "-infinity"
+	}
+	return v.Time.Format(dateFormat)
+}
+
+// order returns the sort field and the connection order, submittals are ordered by id by default
+func (o *submittalLogOrder) order() (submittalOrderField, connection.Order, error) {
+	name := "ID"
+	if o != nil {
+		name = o.Field
+	}
+	field, ok := submittalOrderFields[name]
+	if !ok {
+		return field, connection.Order{}, apperr.Errorf(apperr.ValidationFailed, "cannot order by %s", name)
+	}
+	desc := o != nil && o.Direction != nil && *o.Direction == "DESC"
+	return field, connection.Order{Column: field.column, Desc: desc}, nil
+}
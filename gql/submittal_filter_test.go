@@ -0,0 +1,22 @@
+package gql
+
+import (
+	"go-lambda-graphql/services/submittal"
+	"testing"
+
+	"github.com/graph-gophers/graphql-go"
+)
+
+func TestSubmittalLogFilter(t *testing.T) {
+	t.Run("overdue for is checked against today in go", func(t *testing.T) {
+		approver := graphql.ID("7")
+		c, err := (&submittalLogFilter{OverdueFor: &approver}).compile()
+		if err != nil {
+			t.Fatal(err)
+		}
+		today := submittal.Today().Format(submittal.DateFormat)
+		if len(c.Args) != 2 || c.Args[0] != int64(7) || c.Args[1] != today {
+			t.Errorf("expected the approver id and %s, got %v", today, c.Args)
+		}
+	})
+}
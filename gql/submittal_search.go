@@ -0,0 +1,134 @@
+package gql
+
+import (
+	"context"
+	"go-lambda-graphql/models"
+	"go-lambda-graphql/services/apperr"
+	"go-lambda-graphql/services/connection"
+	"html"
+	"strings"
+
+	"github.com/lib/pq"
+	. "github.com/volatiletech/sqlboiler/queries/qm"
+)
+
+// snippetOptions marks the matches with control characters so the snippet can be escaped before they become <mark> tags
+const snippetOptions = "StartSel=\x01, StopSel=\x02, MaxFragments=2, MinWords=8, MaxWords=20, FragmentDelimiter=\" … \""
+
+// snippetMarks turns the match markers of an escaped snippet into <mark> tags
+var snippetMarks = strings.NewReplacer("\x01", "<mark>", "\x02", "</mark>")
+
+// submittalSearchRow is a submittal with its search rank and snippet
+type submittalSearchRow struct {
+	submittalRow `boil:",bind"`
+	Rank         float64 `boil:"search_rank"`
+	Snippet      string  `boil:"search_snippet"`
+}
+
+// SubmittalSearchConnectionResolver struct
+type SubmittalSearchConnectionResolver struct {
+	E     []*SubmittalSearchEdgeResolver
+	P     connection.PageInfo
+	Total int32
+}
+
+// SubmittalSearchEdgeResolver struct
+type SubmittalSearchEdgeResolver struct {
+	C string
+	N *SubmittalLogFactResolver
+	R float64
+	S string
+}
+
+// Search field runs a full text search over the submittals, best matches first
+func (r *Resolver) Search(ctx context.Context, args struct {
+	Query string
+	First *int32
+	After *string
+}) (*SubmittalSearchConnectionResolver, error) {
+	current, _, err := viewer(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(args.Query) == "" {
+		return nil, apperr.New(apperr.ValidationFailed, "query cannot be empty")
+	}
+	scope, err := submittalScope(ctx, current, nil)
+	if err != nil {
+		return nil, err
+	}
+	// the keyset order cannot take arguments, so the query is inlined as a quoted literal,
+	// without question marks that sqlboiler would take for placeholders, plainto_tsquery drops punctuation anyway
+	tsquery := "plainto_tsquery('english', " + pq.QuoteLiteral(strings.Replace(args.Query, "?", " ", -1)) + ")"
+	rank := "ts_rank(search_vector, " + tsquery + ")"
+	page, err := connection.NewPage(connection.Args{First: args.First, After: args.After}, connection.Order{Column: rank, Desc: true})
+	if err != nil {
+		return nil, err
+	}
+	mods := append(scope, Where("search_vector @@ "+tsquery))
+	total, err := models.SubmittalLogFactsG(mods...).Count()
+	if err != nil {
+		return nil, err
+	}
+	selects := Select(
+		rank+" as search_rank",
+		"ts_headline('english', concat_ws(' ', title, description), "+tsquery+", "+pq.QuoteLiteral(snippetOptions)+") as search_snippet",
+	)
+	var rows []*submittalSearchRow
+	if err := models.SubmittalLogFactsG(append(append([]QueryMod{submittalColumns, selects}, mods...), page.Mods()...)...).Bind(&rows); err != nil {
+		return nil, err
+	}
+	var edges []*SubmittalSearchEdgeResolver
+	var cursors []string
+	for _, i := range page.Indexes(len(rows)) {
+		row := rows[i]
+		cursor := connection.Cursor{Value: row.Rank, ID: row.ID}.Encode()
+		cursors = append(cursors, cursor)
+		edges = append(edges, &SubmittalSearchEdgeResolver{
+			C: cursor,
+			N: &SubmittalLogFactResolver{S: &row.submittalRow},
+			R: row.Rank,
+			S: snippetMarks.Replace(html.EscapeString(row.Snippet)),
+		})
+	}
+	return &SubmittalSearchConnectionResolver{
+		E:     edges,
+		P:     page.PageInfo(cursors),
+		Total: int32(total),
+	}, nil
+}
+
+// Edges returns the edges of the search connection
+func (r *SubmittalSearchConnectionResolver) Edges(ctx context.Context) (*[]*SubmittalSearchEdgeResolver, error) {
+	return &r.E, nil
+}
+
+// PageInfo returns the page info of the search connection
+func (r *SubmittalSearchConnectionResolver) PageInfo(ctx context.Context) (*PageInfoResolver, error) {
+	return &PageInfoResolver{P: r.P}, nil
+}
+
+// TotalCount returns the number of submittals matching the search
+func (r *SubmittalSearchConnectionResolver) TotalCount(ctx context.Context) (int32, error) {
+	return r.Total, nil
+}
+
+// Cursor returns the cursor of the search edge
+func (r *SubmittalSearchEdgeResolver) Cursor(ctx context.Context) (string, error) {
+	return r.C, nil
+}
+
+// Node returns the submittal of the search edge
+func (r *SubmittalSearchEdgeResolver) Node(ctx context.Context) (*SubmittalLogFactResolver, error) {
+	return r.N, nil
+}
+
+// Rank returns how well the submittal matches the search
+func (r *SubmittalSearchEdgeResolver) Rank(ctx context.Context) (float64, error) {
+	return r.R, nil
+}
+
+// Snippet returns the matching fragments of the title and description, html escaped with the matches in <mark> tags
+func (r *SubmittalSearchEdgeResolver) Snippet(ctx context.Context) (string, error) {
+	return r.S, nil
+}
@@ -0,0 +1,180 @@
+package gql
+
+import (
+	"context"
+	"go-lambda-graphql/models"
+	"go-lambda-graphql/services/apperr"
+	"go-lambda-graphql/services/submittal"
+	"strings"
+	"time"
+
+	"github.com/graph-gophers/graphql-go"
+	. "github.com/volatiletech/sqlboiler/queries/qm"
+	"gopkg.in/volatiletech/null.v6"
+)
+
+// submittalOverdueSQL matches the submittals past their final due date on today that are not closed yet,
+// today comes from submittal.Today like the approver statuses, it is inlined since selects take no arguments
+func submittalOverdueSQL(today time.Time) string {
+	return "date_final_due < '" + today.Format(submittal.DateFormat) + "'::date and lower(coalesce(status, '')) <> 'closed'"
+}
+
+// submittalGroup is what a SubmittalLogGroup value groups by, key is the column of the bucket
+// and name the denormalized column naming it, which can differ between the rows of the same key
+type submittalGroup struct {
+	key  string
+	name string
+}
+
+// submittalGroups maps the SubmittalLogGroup values to the columns they group by
+var submittalGroups = map[string]submittalGroup{
+	"PROJECT":                {key: "project_id", name: "project_name"},
+	"STATUS":                 {key: "status"},
+	"RESPONSIBLE_CONTRACTOR": {key: "responsible_contractor_id", name: "responsible_contractor"},
+	"SUBMITTAL_MANAGER":      {key: "submittal_manager_id", name: "submittal_manager"},
+	"SUBMITTAL_TYPE":         {key: "submittal_type"},
+}
+
+// submittalStatsRow is a row of the grouped aggregate query, the keys outside the grouping stay null
+type submittalStatsRow struct {
+	ProjectID                   null.Int64   `boil:"project_id"`
+	ProjectName                 null.String  `boil:"project_name"`
+	Status                      null.String  `boil:"status"`
+	ResponsibleContractorID     null.Int64   `boil:"responsible_contractor_id"`
+	ResponsibleContractor       null.String  `boil:"responsible_contractor"`
+	SubmittalManagerID          null.Int64   `boil:"submittal_manager_id"`
+	SubmittalManager            null.String  `boil:"submittal_manager"`
+	SubmittalType               null.String  `boil:"submittal_type"`
+	Count                       int64        `boil:"count"`
+	OverdueCount                int64        `boil:"overdue_count"`
+	AverageLeadTime             null.Float64 `boil:"average_lead_time"`
+	AverageInternalReviewTime   null.Float64 `boil:"average_internal_review_time"`
+	AverageDesignTeamReviewTime null.Float64 `boil:"average_design_team_review_time"`
+}
+
+// SubmittalStatsBucketResolver struct
+type SubmittalStatsBucketResolver struct {
+	B submittalStatsRow
+}
+
+// SubmittalStats field aggregates the submittals matching the filter, one bucket per combination of the groupBy keys
+func (r *Resolver) SubmittalStats(ctx context.Context, args struct {
+	Filter         *submittalLogFilter
+	GroupBy        []string
+	IncludeDeleted *bool
+}) ([]*SubmittalStatsBucketResolver, error) {
+	current, _, err := viewer(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	scope, err := submittalScope(ctx, current, args.IncludeDeleted)
+	if err != nil {
+		return nil, err
+	}
+	where, err := args.Filter.compile()
+	if err != nil {
+		return nil, err
+	}
+	var keys, names []string
+	seen := map[string]bool{}
+	for _, name := range args.GroupBy {
+		group, ok := submittalGroups[name]
+		if !ok {
+			return nil, apperr.Errorf(apperr.ValidationFailed, "cannot group by %s", name)
+		}
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		keys = append(keys, group.key)
+		if group.name != "" {
+			// a bucket is one id, whatever names its rows carry
+			names = append(names, "max("+group.name+") as "+group.name)
+		}
+	}
+	selects := append(append(append([]string{}, keys...), names...),
+		"count(*) as count",
+		"count(*) filter (where "+submittalOverdueSQL(submittal.Today())+") as overdue_count",
+		"avg(lead_time)::float8 as average_lead_time",
+		"avg(internal_review_time)::float8 as average_internal_review_time",
+		"avg(design_team_review_time)::float8 as average_design_team_review_time",
+	)
+	mods := append(append([]QueryMod{Select(selects...)}, scope...), where.Mods()...)
+	if len(keys) > 0 {
+		mods = append(mods, GroupBy(strings.Join(keys, ", ")), OrderBy(strings.Join(keys, ", ")))
+	}
+	var rows []submittalStatsRow
+	if err := models.SubmittalLogFactsG(mods...).Bind(&rows); err != nil {
+		return nil, err
+	}
+	buckets := make([]*SubmittalStatsBucketResolver, len(rows))
+	for i, row := range rows {
+		buckets[i] = &SubmittalStatsBucketResolver{B: row}
+	}
+	return buckets, nil
+}
+
+// ProjectID returns the project of the bucket when grouped by project
+func (r *SubmittalStatsBucketResolver) ProjectID(ctx context.Context) (*graphql.ID, error) {
+	return nullID(r.B.ProjectID), nil
+}
+
+// ProjectName returns the project name of the bucket when grouped by project
+func (r *SubmittalStatsBucketResolver) ProjectName(ctx context.Context) (*string, error) {
+	return r.B.ProjectName.Ptr(), nil
+}
+
+// Status returns the status of the bucket when grouped by status
+func (r *SubmittalStatsBucketResolver) Status(ctx context.Context) (*string, error) {
+	return r.B.Status.Ptr(), nil
+}
+
+// ResponsibleContractorID returns the responsible contractor of the bucket when grouped by responsible contractor
+func (r *SubmittalStatsBucketResolver) ResponsibleContractorID(ctx context.Context) (*graphql.ID, error) {
+	return nullID(r.B.ResponsibleContractorID), nil
+}
+
+// ResponsibleContractor returns the responsible contractor name of the bucket when grouped by responsible contractor
+func (r *SubmittalStatsBucketResolver) ResponsibleContractor(ctx context.Context) (*string, error) {
+	return r.B.ResponsibleContractor.Ptr(), nil
+}
+
+// SubmittalManagerID returns the submittal manager of the bucket when grouped by submittal manager
+func (r *SubmittalStatsBucketResolver) SubmittalManagerID(ctx context.Context) (*graphql.ID, error) {
+	return nullID(r.B.SubmittalManagerID), nil
+}
+
+// SubmittalManager returns the submittal manager name of the bucket when grouped by submittal manager
+func (r *SubmittalStatsBucketResolver) SubmittalManager(ctx context.Context) (*string, error) {
+	return r.B.SubmittalManager.Ptr(), nil
+}
+
+// SubmittalType returns the submittal type of the bucket when grouped by submittal type
+func (r *SubmittalStatsBucketResolver) SubmittalType(ctx context.Context) (*string, error) {
+	return r.B.SubmittalType.Ptr(), nil
+}
+
+// Count returns the number of submittals in the bucket
+func (r *SubmittalStatsBucketResolver) Count(ctx context.Context) (int32, error) {
+	return int32(r.B.Count), nil
+}
+
+// OverdueCount returns the number of open submittals in the bucket past their final due date
+func (r *SubmittalStatsBucketResolver) OverdueCount(ctx context.Context) (int32, error) {
+	return int32(r.B.OverdueCount), nil
+}
+
+// AverageLeadTime returns the average lead time in days, null when no submittal has one
+func (r *SubmittalStatsBucketResolver) AverageLeadTime(ctx context.Context) (*float64, error) {
+	return r.B.AverageLeadTime.Ptr(), nil
+}
+
+// AverageInternalReviewTime returns the average internal review time in days, null when no submittal has one
+func (r *SubmittalStatsBucketResolver) AverageInternalReviewTime(ctx context.Context) (*float64, error) {
+	return r.B.AverageInternalReviewTime.Ptr(), nil
+}
+
+// AverageDesignTeamReviewTime returns the average design team review time in days, null when no submittal has one
+func (r *SubmittalStatsBucketResolver) AverageDesignTeamReviewTime(ctx context.Context) (*float64, error) {
+	return r.B.AverageDesignTeamReviewTime.Ptr(), nil
+}
@@ -0,0 +1,43 @@
+package gql
+
+import (
+	"database/sql"
+	"go-lambda-graphql/config"
+	"testing"
+
+	_ "github.com/lib/pq"
+	"github.com/volatiletech/sqlboiler/boil"
+	. "github.com/volatiletech/sqlboiler/queries/qm"
+)
+
+func TestSubmittalRows(t *testing.T) {
+	db, _ := sql.Open("postgres", config.ConnectionString)
+	boil.SetDB(db)
+	var id int64
+	err := db.QueryRow(`insert into submittal_log_facts (approver_ids, approver_sent_dates, approver_returned_dates, approver_due_dates)
+		values ('{1,2}', '{2018-03-01,NULL}', '{NULL,NULL}', '{NULL,2018-03-10}') returning id`).Scan(&id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Exec("delete from submittal_log_facts where id = $1", id)
+
+	t.Run("read null date elements", func(t *testing.T) {
+		row, err := oneSubmittalRow(Where("id = ?", id))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if row == nil || row.ID != id {
+			t.Fatalf("expected submittal %d, got %+v", id, row)
+		}
+		if len(row.SentDates) != 2 || row.SentDates[0] == nil || row.SentDates[1] != nil {
+			t.Errorf("unexpected sent dates %v", row.SentDates)
+		}
+		if len(row.ReturnedDates) != 2 || row.ReturnedDates[0] != nil || row.ReturnedDates[1] != nil {
+			t.Errorf("unexpected returned dates %v", row.ReturnedDates)
+		}
+		approvers := (&SubmittalLogFactResolver{S: row}).approvers()
+		if len(approvers) != 2 || approvers[0].Due != nil || approvers[1].Due == nil {
+			t.Errorf("unexpected approvers %+v", approvers)
+		}
+	})
+}
@@ -0,0 +1,193 @@
+package gql
+
+import (
+	"context"
+	"fmt"
+	"go-lambda-graphql/models"
+	"go-lambda-graphql/services/apperr"
+	"go-lambda-graphql/services/trend"
+	"strings"
+	"time"
+
+	"github.com/graph-gophers/graphql-go"
+	"github.com/lib/pq"
+	. "github.com/volatiletech/sqlboiler/queries/qm"
+	"gopkg.in/volatiletech/null.v6"
+)
+
+// trendEvent is the calendar day submittal activity happened on, day is given the quoted time zone
+type trendEvent struct {
+	day  func(zone string) string
+	join string
+}
+
+// trendEvents maps the TrendEvent values to their day, created_at is stored in UTC and shifted to the time zone,
+// the date columns already hold calendar days
+var trendEvents = map[string]trendEvent{
+	"CREATED": {day: func(zone string) string {
+		return fmt.Sprintf("(created_at at time zone 'UTC' at time zone %s)::date", zone)
+	}},
+	"DISTRIBUTED": {day: func(string) string { return "date_distributed" }},
+	"RETURNED": {
+		day:  func(string) string { return "returned.day" },
+		join: "unnest(approver_returned_dates) as returned(day) on true",
+	},
+}
+
+// bucket returns the first day of the bucket of the event, zone went through LoadLocation
+// and is quoted, so it can be inlined in the select
+func (e trendEvent) bucket(interval trend.Interval, zone string) string {
+	return "date_trunc('" + string(interval) + "', " + e.day(pq.QuoteLiteral(zone)) + ")::date"
+}
+
+// trendColumns selects the series key, its name and the bucket, a series is one id whatever names its rows carry
+// so the name is picked per id over the whole result, not per bucket
+func trendColumns(key [2]string, bucket string) []string {
+	return []string{
+		key[0] + " as key_id",
+		"max(max(" + key[1] + ")) over (partition by " + key[0] + ") as key_name",
+		bucket + " as bucket",
+		"count(*) as count",
+	}
+}
+
+// trendGroups maps the TrendGroup values to the id and name columns of a series
+var trendGroups = map[string][2]string{
+	"PROJECT":                {"project_id", "project_name"},
+	"RESPONSIBLE_CONTRACTOR": {"responsible_contractor_id", "responsible_contractor"},
+}
+
+// trendRow is the count of a bucket of a series
+type trendRow struct {
+	KeyID   null.Int64  `boil:"key_id"`
+	KeyName null.String `boil:"key_name"`
+	Bucket  time.Time   `boil:"bucket"`
+	Count   int         `boil:"count"`
+}
+
+// TrendSeriesResolver struct
+type TrendSeriesResolver struct {
+	K null.Int64
+	N null.String
+	P []trend.Point
+}
+
+// TrendPointResolver struct
+type TrendPointResolver struct {
+	P trend.Point
+}
+
+// SubmittalTrends field counts submittal activity per day, week or month, one series per project or contractor
+func (r *Resolver) SubmittalTrends(ctx context.Context, args struct {
+	Event    string
+	Interval string
+	From     Date
+	To       Date
+	GroupBy  *string
+	TimeZone *string
+	Filter   *submittalLogFilter
+}) ([]*TrendSeriesResolver, error) {
+	current, _, err := viewer(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	event, ok := trendEvents[args.Event]
+	if !ok {
+		return nil, apperr.Errorf(apperr.ValidationFailed, "unknown event %s", args.Event)
+	}
+	interval := trend.Interval(strings.ToLower(args.Interval))
+	buckets, err := trend.Buckets(args.From.Time, args.To.Time, interval)
+	if err != nil {
+		return nil, err
+	}
+	zone := "UTC"
+	if args.TimeZone != nil {
+		if _, err := time.LoadLocation(*args.TimeZone); err != nil {
+			return nil, apperr.Errorf(apperr.ValidationFailed, "unknown time zone %s", *args.TimeZone)
+		}
+		zone = *args.TimeZone
+	}
+	key := [2]string{"null::bigint", "null::text"}
+	if args.GroupBy != nil {
+		if key, ok = trendGroups[*args.GroupBy]; !ok {
+			return nil, apperr.Errorf(apperr.ValidationFailed, "cannot group by %s", *args.GroupBy)
+		}
+	}
+	scope, err := submittalScope(ctx, current, nil)
+	if err != nil {
+		return nil, err
+	}
+	where, err := args.Filter.compile()
+	if err != nil {
+		return nil, err
+	}
+	bucket := event.bucket(interval, zone)
+	mods := []QueryMod{Select(trendColumns(key, bucket)...)}
+	if event.join != "" {
+		mods = append(mods, InnerJoin(event.join))
+	}
+	mods = append(mods, scope...)
+	mods = append(mods, where.Mods()...)
+	mods = append(mods,
+		Where(bucket+" between ? and ?", buckets[0].Format(trend.DateFormat), buckets[len(buckets)-1].Format(trend.DateFormat)),
+		GroupBy("key_id, bucket"),
+		OrderBy("key_name, key_id"),
+	)
+	var rows []trendRow
+	if err := models.SubmittalLogFactsG(mods...).Bind(&rows); err != nil {
+		return nil, err
+	}
+	var series []*TrendSeriesResolver
+	var counts []map[string]int
+	for _, row := range rows {
+		last := len(series) - 1
+		if last < 0 || series[last].K != row.KeyID {
+			series = append(series, &TrendSeriesResolver{K: row.KeyID, N: row.KeyName})
+			counts = append(counts, map[string]int{})
+			last++
+		}
+		counts[last][row.Bucket.Format(trend.DateFormat)] += row.Count
+	}
+	for i, s := range series {
+		s.P = trend.Fill(buckets, counts[i])
+	}
+	return series, nil
+}
+
+// KeyID returns the project or contractor of the series, null when the trend is not grouped
+func (r *TrendSeriesResolver) KeyID(ctx context.Context) (*graphql.ID, error) {
+	return nullID(r.K), nil
+}
+
+// Name returns the project or contractor name of the series
+func (r *TrendSeriesResolver) Name(ctx context.Context) (*string, error) {
+	return r.N.Ptr(), nil
+}
+
+// Points returns a point for every bucket of the range, empty buckets count zero
+func (r *TrendSeriesResolver) Points(ctx context.Context) ([]*TrendPointResolver, error) {
+	l := make([]*TrendPointResolver, len(r.P))
+	for i, p := range r.P {
+		l[i] = &TrendPointResolver{P: p}
+	}
+	return l, nil
+}
+
+// Total returns the count of the whole series
+func (r *TrendSeriesResolver) Total(ctx context.Context) (int32, error) {
+	total := 0
+	for _, p := range r.P {
+		total += p.Count
+	}
+	return int32(total), nil
+}
+
+// Bucket returns the first day of the bucket
+func (r *TrendPointResolver) Bucket(ctx context.Context) (Date, error) {
+	return Date{Time: r.P.Bucket}, nil
+}
+
+// Count returns the activity of the bucket
+func (r *TrendPointResolver) Count(ctx context.Context) (int32, error) {
+	return int32(r.P.Count), nil
+}
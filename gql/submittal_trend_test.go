@@ -0,0 +1,28 @@
+package gql
+
+import (
+	"go-lambda-graphql/services/trend"
+	"testing"
+)
+
+func TestSubmittalTrends(t *testing.T) {
+	t.Run("bucket the events in the time zone", func(t *testing.T) {
+		for event, expected := range map[string]string{
+			"CREATED":     "date_trunc('week', (created_at at time zone 'UTC' at time zone 'America/New_York')::date)::date",
+			"DISTRIBUTED": "date_trunc('week', date_distributed)::date",
+			"RETURNED":    "date_trunc('week', returned.day)::date",
+		} {
+			if bucket := trendEvents[event].bucket(trend.Week, "America/New_York"); bucket != expected {
+				t.Errorf("%s: expected %s, got %s", event, expected, bucket)
+			}
+		}
+	})
+
+	t.Run("name a series after its id", func(t *testing.T) {
+		columns := trendColumns(trendGroups["PROJECT"], "bucket")
+		expected := "max(max(project_name)) over (partition by project_id) as key_name"
+		if columns[0] != "project_id as key_id" || columns[1] != expected {
+			t.Errorf("unexpected columns %v", columns)
+		}
+	})
+}
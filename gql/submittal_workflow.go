@@ -0,0 +1,131 @@
+package gql
+
+import (
+	"context"
+	"go-lambda-graphql/services/submittal"
+	"strconv"
+	"time"
+
+	"github.com/graph-gophers/graphql-go"
+)
+
+// SubmittalApproverResolver struct
+type SubmittalApproverResolver struct {
+	A     submittal.Approver
+	Today time.Time
+}
+
+// BallInCourtPartyResolver struct
+type BallInCourtPartyResolver struct {
+	P submittal.Party
+}
+
+// approvers zips the approver columns of the submittal
+func (r *SubmittalLogFactResolver) approvers() []submittal.Approver {
+	return submittal.Approvers(
+		r.S.ApproverIDs,
+		r.S.ApproverVendorIDs,
+		r.S.ApproverNames,
+		r.S.ApproverResponses,
+		r.S.SentDates,
+		r.S.DueDates,
+		r.S.ReturnedDates,
+	)
+}
+
+// Approvers returns the approval workflow of the submittal with the status of each approver
+func (r *SubmittalLogFactResolver) Approvers(ctx context.Context) ([]*SubmittalApproverResolver, error) {
+	today := submittal.Today()
+	approvers := r.approvers()
+	l := make([]*SubmittalApproverResolver, len(approvers))
+	for i, a := range approvers {
+		l[i] = &SubmittalApproverResolver{A: a, Today: today}
+	}
+	return l, nil
+}
+
+// BallInCourt returns the parties the submittal is currently waiting on
+func (r *SubmittalLogFactResolver) BallInCourt(ctx context.Context) ([]*BallInCourtPartyResolver, error) {
+	parties := submittal.BallInCourt(r.S.BallInCourtIDs, r.S.BallInCourtNames, r.S.BallInCourtDueDates, r.approvers())
+	l := make([]*BallInCourtPartyResolver, len(parties))
+	for i, p := range parties {
+		l[i] = &BallInCourtPartyResolver{P: p}
+	}
+	return l, nil
+}
+
+// DaysOverdue returns the days overdue of the most overdue approver
+func (r *SubmittalLogFactResolver) DaysOverdue(ctx context.Context) (int32, error) {
+	return int32(submittal.DaysOverdue(r.approvers(), submittal.Today())), nil
+}
+
+// ID returns the id of the approver
+func (r *SubmittalApproverResolver) ID(ctx context.Context) (graphql.ID, error) {
+	return graphql.ID(strconv.FormatInt(r.A.ID, 10)), nil
+}
+
+// VendorID returns the vendor of the approver
+func (r *SubmittalApproverResolver) VendorID(ctx context.Context) (*graphql.ID, error) {
+	if r.A.VendorID == 0 {
+		return nil, nil
+	}
+	id := graphql.ID(strconv.FormatInt(r.A.VendorID, 10))
+	return &id, nil
+}
+
+// Name returns the name of the approver
+func (r *SubmittalApproverResolver) Name(ctx context.Context) (string, error) {
+	return r.A.Name, nil
+}
+
+// Response returns the response of the approver
+func (r *SubmittalApproverResolver) Response(ctx context.Context) (string, error) {
+	return r.A.Response, nil
+}
+
+// SentDate returns when the submittal was sent to the approver
+func (r *SubmittalApproverResolver) SentDate(ctx context.Context) (*Date, error) {
+	return datePtr(r.A.Sent), nil
+}
+
+// DueDate returns when the approver has to return the submittal
+func (r *SubmittalApproverResolver) DueDate(ctx context.Context) (*Date, error) {
+	return datePtr(r.A.Due), nil
+}
+
+// ReturnedDate returns when the approver returned the submittal
+func (r *SubmittalApproverResolver) ReturnedDate(ctx context.Context) (*Date, error) {
+	return datePtr(r.A.Returned), nil
+}
+
+// Status returns where the approver stands in the review
+func (r *SubmittalApproverResolver) Status(ctx context.Context) (string, error) {
+	return string(r.A.Status(r.Today)), nil
+}
+
+// DaysOverdue returns how many days past due the approver is, or returned the submittal
+func (r *SubmittalApproverResolver) DaysOverdue(ctx context.Context) (int32, error) {
+	return int32(r.A.DaysOverdue(r.Today)), nil
+}
+
+// ID returns the id of the party
+func (r *BallInCourtPartyResolver) ID(ctx context.Context) (graphql.ID, error) {
+	return graphql.ID(strconv.FormatInt(r.P.ID, 10)), nil
+}
+
+// Name returns the name of the party
+func (r *BallInCourtPartyResolver) Name(ctx context.Context) (string, error) {
+	return r.P.Name, nil
+}
+
+// DueDate returns when the party has to act on the submittal
+func (r *BallInCourtPartyResolver) DueDate(ctx context.Context) (*Date, error) {
+	return datePtr(r.P.Due), nil
+}
+
+func datePtr(t *time.Time) *Date {
+	if t == nil {
+		return nil
+	}
+	return &Date{Time: *t}
+}
@@ -0,0 +1,224 @@
+package gql
+
+import (
+	"context"
+	"encoding/json"
+	"go-lambda-graphql/models"
+	"go-lambda-graphql/services/apperr"
+	"go-lambda-graphql/services/notify"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/graph-gophers/graphql-go"
+	. "github.com/volatiletech/sqlboiler/queries/qm"
+)
+
+// the postgres channels the subscriptions listen on
+const (
+	// submittalStatusChannel is notified by a trigger when the status of a submittal changes
+	submittalStatusChannel = "submittal_status"
+	// userUpdatedChannel is notified by UpdateUser with the id of the usr row
+	userUpdatedChannel = "usr_updated"
+)
+
+// NotificationChannels lists the channels the listener given to UseNotifications must listen on
+var NotificationChannels = []string{submittalStatusChannel, userUpdatedChannel}
+
+var notifications *notify.Listener
+
+var errNoSubscriptions = apperr.New(apperr.Internal, "subscriptions are not available")
+
+// UseNotifications sets the listener the subscriptions are fed from, without one subscribing fails
+func UseNotifications(l *notify.Listener) {
+	notifications = l
+}
+
+// InitSubscription authenticates a websocket connection from the authToken of its connection_init payload,
+// browsers cannot set headers on websockets, connections authenticated by the auth cookie send no token
+func InitSubscription(ctx context.Context, payload json.RawMessage) (context.Context, error) {
+	var p struct {
+		AuthToken     string `json:"authToken"`
+		Authorization string `json:"Authorization"`
+	}
+	if len(payload) > 0 {
+		if err := json.Unmarshal(payload, &p); err != nil {
+			return nil, apperr.New(apperr.ValidationFailed, "invalid connection payload")
+		}
+	}
+	tokenString := p.AuthToken
+	if len(p.Authorization) > 7 && strings.EqualFold(p.Authorization[:7], "bearer ") {
+		tokenString = strings.TrimSpace(p.Authorization[7:])
+	}
+	if tokenString == "" {
+		return ctx, nil
+	}
+	ctx = withPrincipal(ctx, tokenString)
+	if _, _, err := viewer(ctx, nil); err != nil {
+		return nil, err
+	}
+	return ctx, nil
+}
+
+// submittalStatusEvent is the payload of the submittal_status notifications
+type submittalStatusEvent struct {
+	ID             int64   `json:"id"`
+	Status         *string `json:"status"`
+	PreviousStatus *string `json:"previous_status"`
+}
+
+// SubmittalStatusChangeResolver struct
+type SubmittalStatusChangeResolver struct {
+	S        *submittalRow
+	Previous *string
+}
+
+// SubmittalStatusChanged subscription pushes the submittals the viewer can see when their status changes,
+// it completes when the token of the viewer expires or is revoked
+func (r *Resolver) SubmittalStatusChanged(ctx context.Context, args struct {
+	ProjectID *graphql.ID
+}) (<-chan *SubmittalStatusChangeResolver, error) {
+	_, claims, err := viewer(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	if notifications == nil {
+		return nil, errNoSubscriptions
+	}
+	projectID, err := parseNullID(args.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	c := make(chan *SubmittalStatusChangeResolver)
+	go func() {
+		defer close(c)
+		for payload := range notifications.Subscribe(ctx, submittalStatusChannel) {
+			var event submittalStatusEvent
+			if err := json.Unmarshal([]byte(payload), &event); err != nil {
+				log.Println("submittal status notification:", err)
+				continue
+			}
+			// the token may expire or be revoked while subscribed, the subscription completes with it
+			usr, err := claimsUser(claims)
+			if _, ok := err.(*apperr.Error); ok {
+				return
+			}
+			if err != nil {
+				log.Println("submittal status notification:", err)
+				continue
+			}
+			// memberships and permissions may change while subscribed, every event is checked afresh
+			eventCtx := context.WithValue(ctx, loadersKey{}, newLoaders())
+			scope, err := submittalScope(eventCtx, usr, nil)
+			if err != nil {
+				log.Println("submittal status notification:", err)
+				continue
+			}
+			mods := append(scope, Where("id = ?", event.ID))
+			if projectID.Valid {
+				mods = append(mods, Where("project_id = ?", projectID.Int64))
+			}
+			submittal, err := oneSubmittalRow(mods...)
+			if err != nil {
+				log.Println("submittal status notification:", err)
+				continue
+			}
+			if submittal == nil {
+				// not visible to the viewer or outside the project
+				continue
+			}
+			select {
+			case c <- &SubmittalStatusChangeResolver{S: submittal, Previous: event.PreviousStatus}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return c, nil
+}
+
+// UserUpdated subscription pushes a user every time UpdateUser changes it, the viewer when no id is given,
+// only the viewer and the members of their companies can be followed.
+// It completes when the token of the viewer expires or is revoked, by a password change for example,
+// or when the user no longer shares a company with the viewer
+func (r *Resolver) UserUpdated(ctx context.Context, args struct {
+	ID *graphql.ID
+}) (<-chan *UserResolver, error) {
+	current, claims, err := viewer(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	id := current.ID
+	if args.ID != nil {
+		if id, err = parseUserID(*args.ID); err != nil {
+			return nil, err
+		}
+	}
+	shared, err := sharesCompany(current, id)
+	if err != nil {
+		return nil, err
+	}
+	if !shared {
+		return nil, errForbidden
+	}
+	if notifications == nil {
+		return nil, errNoSubscriptions
+	}
+	c := make(chan *UserResolver)
+	go func() {
+		defer close(c)
+		for payload := range notifications.Subscribe(ctx, userUpdatedChannel) {
+			if payload != strconv.FormatInt(id, 10) {
+				continue
+			}
+			// the token may expire or be revoked while subscribed, by a password change for example,
+			// the subscription completes with it
+			current, err := claimsUser(claims)
+			if _, ok := err.(*apperr.Error); ok {
+				return
+			}
+			if err != nil {
+				log.Println("user updated notification:", err)
+				continue
+			}
+			// the memberships may have changed since subscribing too
+			shared, err := sharesCompany(current, id)
+			if err != nil {
+				log.Println("user updated notification:", err)
+				continue
+			}
+			if !shared {
+				return
+			}
+			// the loaders of the connection cached the row as it was when subscribing
+			usr := current
+			if id != current.ID {
+				if usr, err = models.FindUsrG(id); err != nil {
+					log.Println("user updated notification:", err)
+					continue
+				}
+			}
+			select {
+			case c <- &UserResolver{V: userFromModel(current), U: userFromModel(usr)}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return c, nil
+}
+
+// Submittal returns the submittal as it is after the change
+func (r *SubmittalStatusChangeResolver) Submittal(ctx context.Context) (*SubmittalLogFactResolver, error) {
+	return &SubmittalLogFactResolver{S: r.S}, nil
+}
+
+// Status returns the new status of the submittal
+func (r *SubmittalStatusChangeResolver) Status(ctx context.Context) (*string, error) {
+	return r.S.Status.Ptr(), nil
+}
+
+// PreviousStatus returns the status of the submittal before the change
+func (r *SubmittalStatusChangeResolver) PreviousStatus(ctx context.Context) (*string, error) {
+	return r.Previous, nil
+}
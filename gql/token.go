@@ -0,0 +1,167 @@
+package gql
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"go-lambda-graphql/config"
+	"go-lambda-graphql/models"
+	"go-lambda-graphql/services/apperr"
+	"go-lambda-graphql/services/auth"
+	"go-lambda-graphql/services/generate"
+	"time"
+
+	"github.com/graph-gophers/graphql-go"
+	"github.com/volatiletech/sqlboiler/boil"
+	. "github.com/volatiletech/sqlboiler/queries/qm"
+	"gopkg.in/volatiletech/null.v6"
+)
+
+var errInvalidRefreshToken = apperr.New(apperr.Unauthenticated, "invalid refresh token")
+
+// Token struct
+type Token struct {
+	Jwt          string
+	RefreshToken string
+	Expires      graphql.Time
+}
+
+// TokenResolver struct
+type TokenResolver struct {
+	T *Token
+}
+
+// accessToken signs a short lived jwt for the user
+func accessToken(usr *models.Usr) (string, graphql.Time, error) {
+	claims := auth.NewClaims()
+	claims["id"] = usr.ID
+	claims["email"] = usr.Email
+	claims["created"] = usr.CreatedAt
+	claims["updated"] = usr.UpdatedAt
+	claims["name"] = usr.Name
+	claims["ver"] = usr.TokenVersion
+	expires := graphql.Time{Time: time.Unix(claims["exp"].(int64), 0)}
+	tokenString, err := auth.SignToken(claims)
+	return tokenString, expires, err
+}
+
+func hashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// issueRefreshToken stores a new opaque single use refresh token, only its hash is persisted.
+// Every token exchanged from the same login shares a family so reuse can revoke all of them.
+func issueRefreshToken(exec boil.Executor, usrID int64, family string) (string, error) {
+	token := generate.GenerateRandomString(32)
+	refreshToken := models.RefreshToken{
+		UsrID:     usrID,
+		TokenHash: hashRefreshToken(token),
+		Family:    family,
+		ExpiresAt: time.Now().Add(config.RefreshTokenTTL),
+	}
+	return token, refreshToken.Insert(exec)
+}
+
+// newToken signs an access token and pairs it with a refresh token
+func newToken(usr *models.Usr, refreshToken string) (*TokenResolver, error) {
+	tokenString, expires, err := accessToken(usr)
+	if err != nil {
+		return nil, err
+	}
+	return &TokenResolver{
+		T: &Token{
+			Jwt:          tokenString,
+			RefreshToken: refreshToken,
+			Expires:      expires,
+		},
+	}, nil
+}
+
+// Login mutation
+func (r *Resolver) Login(ctx context.Context, args struct {
+	Email    string
+	Password string
+}) (*TokenResolver, error) {
+	usr, err := models.UsrsG(Where("email = ?", args.Email)).One()
+	if err != nil {
+		return nil, errWrongCredentials
+	}
+	validPassword := auth.CheckPasswordHash(args.Password, usr.PasswordHash)
+	if !validPassword {
+		return nil, errWrongCredentials
+	}
+	tx, err := boil.Begin()
+	if err != nil {
+		return nil, err
+	}
+	refreshToken, err := issueRefreshToken(tx, usr.ID, generate.GenerateRandomHexString(16))
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	tx.Commit()
+	return newToken(usr, refreshToken)
+}
+
+// RefreshToken mutation exchanges a refresh token for a new access and refresh token
+func (r *Resolver) RefreshToken(ctx context.Context, args struct {
+	Token string
+}) (*TokenResolver, error) {
+	tx, err := boil.Begin()
+	if err != nil {
+		return nil, err
+	}
+	current, err := models.RefreshTokens(tx, Where("token_hash = ?", hashRefreshToken(args.Token)), For("update")).One()
+	if err != nil {
+		tx.Rollback()
+		return nil, errInvalidRefreshToken
+	}
+	now := time.Now()
+	if current.RevokedAt.Valid || now.After(current.ExpiresAt) {
+		tx.Rollback()
+		return nil, errInvalidRefreshToken
+	}
+	if current.UsedAt.Valid {
+		// a spent token coming back means it leaked, revoke every token of that login
+		err := models.RefreshTokens(tx, Where("family = ?", current.Family)).UpdateAll(models.M{"revoked_at": now})
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		tx.Commit()
+		return nil, errInvalidRefreshToken
+	}
+	current.UsedAt = null.TimeFrom(now)
+	if err := current.Update(tx, "used_at"); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	usr, err := models.FindUsr(tx, current.UsrID)
+	if err != nil {
+		tx.Rollback()
+		return nil, errInvalidRefreshToken
+	}
+	refreshToken, err := issueRefreshToken(tx, usr.ID, current.Family)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	tx.Commit()
+	return newToken(usr, refreshToken)
+}
+
+// Jwt returns the access token from Token resolver
+func (r *TokenResolver) Jwt(ctx context.Context) (string, error) {
+	return r.T.Jwt, nil
+}
+
+// RefreshToken returns the refresh token from Token resolver
+func (r *TokenResolver) RefreshToken(ctx context.Context) (string, error) {
+	return r.T.RefreshToken, nil
+}
+
+// Expires returns the access token expiry from Token resolver
+func (r *TokenResolver) Expires(ctx context.Context) (graphql.Time, error) {
+	return r.T.Expires, nil
+}
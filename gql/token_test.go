@@ -0,0 +1,65 @@
+package gql
+
+import (
+	"context"
+	"database/sql"
+	"go-lambda-graphql/config"
+	"go-lambda-graphql/models"
+	"go-lambda-graphql/services/generate"
+	"testing"
+
+	_ "github.com/lib/pq"
+	"github.com/volatiletech/sqlboiler/boil"
+	. "github.com/volatiletech/sqlboiler/queries/qm"
+)
+
+func TestRefreshToken(t *testing.T) {
+	db, _ := sql.Open("postgres", config.ConnectionString)
+	boil.SetDB(db)
+	ctx := context.Background()
+	r := &Resolver{}
+	email := generate.GenerateRandomHexString(8) + "@example.com"
+	password := generate.GenerateRandomHexString(8)
+	payload, err := r.Signup(ctx, struct {
+		Email    string
+		Name     string
+		Password string
+	}{Email: email, Name: "Refresh Tester", Password: password})
+	if err != nil || payload.U == nil {
+		t.Fatalf("unexpected signup %+v, %v", payload, err)
+	}
+	login, err := r.Login(ctx, struct {
+		Email    string
+		Password string
+	}{Email: email, Password: password})
+	if err != nil {
+		t.Fatal(err)
+	}
+	refresh := func(token string) (*TokenResolver, error) {
+		return r.RefreshToken(ctx, struct{ Token string }{Token: token})
+	}
+
+	t.Run("exchange a refresh token once", func(t *testing.T) {
+		rotated, err := refresh(login.T.RefreshToken)
+		if err != nil || rotated.T.Jwt == "" || rotated.T.RefreshToken == login.T.RefreshToken {
+			t.Fatalf("unexpected rotation %+v, %v", rotated, err)
+		}
+
+		t.Run("revoke the family when a spent token comes back", func(t *testing.T) {
+			if _, err := refresh(login.T.RefreshToken); err != errInvalidRefreshToken {
+				t.Errorf("expected the reused token to be refused, got %v", err)
+			}
+			if _, err := refresh(rotated.T.RefreshToken); err != errInvalidRefreshToken {
+				t.Errorf("expected the rotated token to be revoked with its family, got %v", err)
+			}
+			usr, err := models.UsrsG(Where("email = ?", email)).One()
+			if err != nil {
+				t.Fatal(err)
+			}
+			live, err := models.RefreshTokensG(Where("usr_id = ? and revoked_at is null", usr.ID)).Count()
+			if err != nil || live != 0 {
+				t.Errorf("expected every token of the family to be revoked, %d left, %v", live, err)
+			}
+		})
+	})
+}
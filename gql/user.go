@@ -2,20 +2,19 @@ package gql
 
 import (
 	"context"
-	"errors"
-	"go-lambda-graphql/config"
 	"go-lambda-graphql/models"
+	"go-lambda-graphql/services/apperr"
 	"go-lambda-graphql/services/auth"
+	"go-lambda-graphql/services/connection"
 	"strconv"
-	"time"
 
 	"github.com/volatiletech/sqlboiler/boil"
+	"github.com/volatiletech/sqlboiler/queries"
 
-	"github.com/dgrijalva/jwt-go"
 	"github.com/go-ozzo/ozzo-validation"
 	"github.com/go-ozzo/ozzo-validation/is"
-	"github.com/neelance/graphql-go"
-	"github.com/neelance/graphql-go/relay"
+	"github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/relay"
 	. "github.com/volatiletech/sqlboiler/queries/qm"
 )
 
@@ -37,53 +36,107 @@ type ID struct {
 	ID string
 }
 
-// Signup mutation
+// UserConnectionResolver struct
+type UserConnectionResolver struct {
+	E     []*UserEdgeResolver
+	P     connection.PageInfo
+	Total int32
+}
+
+// UserEdgeResolver struct
+type UserEdgeResolver struct {
+	C string
+	N *UserResolver
+}
+
+// rowID returns the usr table id encoded in the global id
+func (u *User) rowID() (int64, error) {
+	var spec ID
+	if err := relay.UnmarshalSpec(u.ID, &spec); err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(spec.ID, 10, 64)
+}
+
+// parseUserID reads the usr table id of a User global id
+func parseUserID(id graphql.ID) (int64, error) {
+	var spec ID
+	if relay.UnmarshalKind(id) != "usr" || relay.UnmarshalSpec(id, &spec) != nil {
+		return 0, apperr.Errorf(apperr.ValidationFailed, "invalid id %s", id)
+	}
+	rowID, err := strconv.ParseInt(spec.ID, 10, 64)
+	if err != nil {
+		return 0, apperr.New(apperr.ValidationFailed, "invalid user id")
+	}
+	return rowID, nil
+}
+
+// userFromModel maps a usr row to the User graphql type
+func userFromModel(u *models.Usr) *User {
+	return &User{
+		Entity: Entity{
+			ID:      relay.MarshalID("usr", ID{strconv.FormatInt(u.ID, 10)}),
+			Created: graphql.Time{Time: u.CreatedAt},
+			Updated: graphql.Time{Time: u.UpdatedAt},
+		},
+		Name:  u.Name,
+		Email: u.Email,
+	}
+}
+
+// Signup mutation creates a user, the payload lists the invalid arguments instead when there are some
 func (r *Resolver) Signup(ctx context.Context, args struct {
 	Email    string
 	Name     string
 	Password string
-}) (*UserResolver, error) {
-	tx, error := boil.Begin()
-	if error != nil {
-		return nil, error
-	}
-	hasEmail, _ := models.Usrs(tx, Where("email = ?", args.Email)).Exists()
-	if hasEmail {
-		return nil, errors.New("email taken")
+}) (*UserPayloadResolver, error) {
+	var userErrors []*UserErrorResolver
+	userErrors = validateField(userErrors, "email", args.Email,
+		fieldRule{userErrorRequired, validation.Required},
+		fieldRule{userErrorLength, validation.Length(5, 50)},
+		fieldRule{userErrorFormat, is.Email},
+	)
+	// a valid email is looked up right away, so a taken one is reported with the errors of the other arguments
+	if len(userErrors) == 0 {
+		taken, err := models.UsrsG(Where("email = ?", args.Email)).Exists()
+		if err != nil {
+			return nil, err
+		}
+		if taken {
+			userErrors = append(userErrors, takenEmail())
+		}
 	}
-	err := validation.ValidateStruct(&args,
-		validation.Field(&args.Email, validation.Required, validation.Length(5, 50), is.Email),
-		validation.Field(&args.Name, validation.Required, validation.Length(5, 50)),
-		validation.Field(&args.Password, validation.Required, validation.Length(5, 0)),
+	userErrors = validateField(userErrors, "name", args.Name,
+		fieldRule{userErrorRequired, validation.Required},
+		fieldRule{userErrorLength, validation.Length(5, 50)},
+	)
+	userErrors = validateField(userErrors, "password", args.Password,
+		fieldRule{userErrorRequired, validation.Required},
+		fieldRule{userErrorLength, validation.Length(5, 0)},
 	)
+	if len(userErrors) > 0 {
+		return &UserPayloadResolver{E: userErrors}, nil
+	}
+	hash, err := auth.HashPassword(args.Password)
 	if err != nil {
 		return nil, err
 	}
-	hash, _ := auth.HashPassword(args.Password)
-	var newUser models.Usr
-	newUser.Name = args.Name
-	newUser.Email = args.Email
-	newUser.PasswordHash = hash
-	insertErr := newUser.Insert(tx)
-	if insertErr != nil {
-		tx.Rollback()
-		return nil, insertErr
+	newUser := models.Usr{
+		Name:         args.Name,
+		Email:        args.Email,
+		PasswordHash: hash,
 	}
-	tx.Commit()
-	usr := &User{
-		Entity: Entity{
-			ID:      relay.MarshalID("usr", ID{strconv.FormatInt(newUser.ID, 10)}),
-			Created: graphql.Time{Time: newUser.CreatedAt},
-			Updated: graphql.Time{Time: newUser.UpdatedAt},
-		},
-		Name:  newUser.Name,
-		Email: newUser.Email,
+	if err := newUser.InsertG(); err != nil {
+		if emailTaken(err) {
+			return &UserPayloadResolver{E: []*UserErrorResolver{takenEmail()}}, nil
+		}
+		return nil, err
 	}
-
-	return &UserResolver{
+	usr := userFromModel(&newUser)
+	return &UserPayloadResolver{U: &UserResolver{
 		U: usr,
 		V: usr,
-	}, nil
+	}}, nil
 }
 
 // Jwt query
@@ -93,119 +146,126 @@ func (r *Resolver) Jwt(ctx context.Context, args struct {
 }) (*string, error) {
 	usr, err := models.UsrsG(Where("email = ?", args.Email)).One()
 	if err != nil {
-		return nil, errors.New("wrong email or password combination")
+		return nil, errWrongCredentials
 	}
 	validPassword := auth.CheckPasswordHash(args.Password, usr.PasswordHash)
 	if !validPassword {
-		return nil, errors.New("wrong email or password combination")
+		return nil, errWrongCredentials
+	}
+	tokenString, _, err := accessToken(usr)
+	if err != nil {
+		return nil, err
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"id":      usr.ID,
-		"email":   usr.Email,
-		"created": usr.CreatedAt,
-		"updated": usr.UpdatedAt,
-		"name":    usr.Name,
-		"nbf":     time.Date(2017, 10, 10, 12, 0, 0, 0, time.UTC).Unix(),
-	})
-	tokenString, err := token.SignedString([]byte(config.JWTSecret))
 	return &tokenString, nil
 }
 
 // Viewer field
 func (r *Resolver) Viewer(ctx context.Context, args struct {
-	Jwt string
+	Jwt *string
 }) (*UserResolver, error) {
-	token, err := auth.GetToken(args.Jwt)
+	current, _, err := viewer(ctx, args.Jwt)
 
 	if err != nil {
 		return nil, err
 	}
 
-	if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-		UserID := claims["id"].(float64)
-		created, _ := time.Parse(time.RFC3339, claims["created"].(string))
-		updated, _ := time.Parse(time.RFC3339, claims["updated"].(string))
-		email := claims["email"].(string)
-		name := claims["name"].(string)
-		usr := &User{
-			Entity: Entity{
-				ID:      relay.MarshalID("usr", ID{strconv.FormatInt(int64(UserID), 10)}),
-				Created: graphql.Time{Time: created},
-				Updated: graphql.Time{Time: updated},
-			},
-			Name:  name,
-			Email: email,
-		}
-
-		return &UserResolver{
-			U: usr,
-			V: usr,
-		}, nil
-	}
-
-	return nil, err
+	usr := userFromModel(current)
+	return &UserResolver{
+		U: usr,
+		V: usr,
+	}, nil
 }
 
-// UpdateUser mutation
+// UpdateUser mutation changes the viewer, the payload lists the invalid arguments instead when there are some
 func (r *Resolver) UpdateUser(ctx context.Context, args struct {
 	Email    *string
 	Name     *string
 	Password *string
-	Jwt      string
-}) (*UserResolver, error) {
-	token, err := auth.GetToken(args.Jwt)
+	Jwt      *string
+}) (*UserPayloadResolver, error) {
+	current, _, err := viewer(ctx, args.Jwt)
+	if err != nil {
+		return nil, err
+	}
 
-	if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-		tx, error := boil.Begin()
-		if error != nil {
-			return nil, error
-		}
-		var dbOverrides []string
-		id := int64(claims["id"].(float64))
-		updatedUser, _ := models.FindUsr(tx, id)
-		updatedUser.ID = id
-		err := validation.ValidateStruct(&args,
-			validation.Field(&args.Email, validation.Length(5, 50), is.Email),
-			validation.Field(&args.Name, validation.Length(5, 50)),
-			validation.Field(&args.Password, validation.Length(5, 0)),
-		)
+	var userErrors []*UserErrorResolver
+	userErrors = validateField(userErrors, "email", args.Email,
+		fieldRule{userErrorRequired, validation.NilOrNotEmpty},
+		fieldRule{userErrorLength, validation.Length(5, 50)},
+		fieldRule{userErrorFormat, is.Email},
+	)
+	if len(userErrors) == 0 && args.Email != nil {
+		taken, err := models.UsrsG(Where("email = ? and id <> ?", *args.Email, current.ID)).Exists()
 		if err != nil {
 			return nil, err
 		}
-		if args.Name != nil {
-			dbOverrides = append(dbOverrides, "name")
-			updatedUser.Name = *args.Name
-		}
-		if args.Email != nil {
-			dbOverrides = append(dbOverrides, "email")
-			updatedUser.Email = *args.Email
-		}
-		if args.Password != nil {
-			dbOverrides = append(dbOverrides, "password_hash")
-			hash, _ := auth.HashPassword(*args.Password)
-			updatedUser.PasswordHash = hash
+		if taken {
+			userErrors = append(userErrors, takenEmail())
 		}
+	}
+	userErrors = validateField(userErrors, "name", args.Name,
+		fieldRule{userErrorRequired, validation.NilOrNotEmpty},
+		fieldRule{userErrorLength, validation.Length(5, 50)},
+	)
+	userErrors = validateField(userErrors, "password", args.Password,
+		fieldRule{userErrorRequired, validation.NilOrNotEmpty},
+		fieldRule{userErrorLength, validation.Length(5, 0)},
+	)
+	if len(userErrors) > 0 {
+		return &UserPayloadResolver{E: userErrors}, nil
+	}
 
-		usr := &User{
-			Entity: Entity{
-				ID:      relay.MarshalID("usr", ID{strconv.FormatInt(updatedUser.ID, 10)}),
-				Created: graphql.Time{Time: updatedUser.CreatedAt},
-				Updated: graphql.Time{Time: updatedUser.UpdatedAt},
-			},
-			Name:  updatedUser.Name,
-			Email: updatedUser.Email,
-		}
-		dbError := updatedUser.Upsert(tx, true, []string{"id"}, dbOverrides)
-		if dbError != nil {
+	tx, err := boil.Begin()
+	if err != nil {
+		return nil, err
+	}
+	var dbOverrides []string
+	updatedUser, err := models.FindUsr(tx, current.ID)
+	if err != nil {
+		tx.Rollback()
+		return nil, errUserNotFound
+	}
+	if args.Name != nil {
+		dbOverrides = append(dbOverrides, "name")
+		updatedUser.Name = *args.Name
+	}
+	if args.Email != nil {
+		dbOverrides = append(dbOverrides, "email")
+		updatedUser.Email = *args.Email
+	}
+	if args.Password != nil {
+		dbOverrides = append(dbOverrides, "password_hash")
+		hash, err := auth.HashPassword(*args.Password)
+		if err != nil {
 			tx.Rollback()
+			return nil, err
+		}
+		updatedUser.PasswordHash = hash
+	}
+
+	dbError := updatedUser.Upsert(tx, true, []string{"id"}, dbOverrides)
+	if dbError == nil && args.Password != nil {
+		// a new password logs out every device holding a token issued with the old one
+		dbError = revokeSessions(tx, updatedUser)
+	}
+	if dbError == nil {
+		// delivered to the userUpdated subscriptions once the transaction commits
+		_, dbError = queries.Raw(tx, "select pg_notify($1, $2)", userUpdatedChannel, strconv.FormatInt(updatedUser.ID, 10)).Exec()
+	}
+	if dbError != nil {
+		tx.Rollback()
+		if emailTaken(dbError) {
+			return &UserPayloadResolver{E: []*UserErrorResolver{takenEmail()}}, nil
 		}
-		tx.Commit()
-		return &UserResolver{
-			V: usr,
-			U: usr,
-		}, nil
+		return nil, dbError
 	}
-	return nil, err
+	tx.Commit()
+	loadersFromContext(ctx).Users.Prime(updatedUser.ID, updatedUser)
+	usr := userFromModel(updatedUser)
+	return &UserPayloadResolver{U: &UserResolver{
+		V: usr,
+		U: usr,
+	}}, nil
 }
 
 // ID returns the id from User resolver
@@ -233,27 +293,66 @@ func (r *UserResolver) Email(ctx context.Context) (string, error) {
 	return r.U.Email, nil
 }
 
-// // TrendingConnection field represents a campaign connection
-// func (r *UserResolver) TrendingConnection(ctx context.Context, args connectionArgs) (*campaignConnectionResolver, error) {
-
-// }
-
-// // TrendingConnection field represents a campaign connection
-// func (r *UserResolver) FriendsConnection(ctx context.Context, args connectionArgs) (*UserConnectionResolver, error) {
-
-// }
+// Edges returns the edges of the User connection
+func (r *UserConnectionResolver) Edges(ctx context.Context) (*[]*UserEdgeResolver, error) {
+	return &r.E, nil
+}
 
-// // TrendingConnection field represents a campaign connection
-// func (r *UserResolver) SearchConnection(ctx context.Context, args searchConnectionArgs) (*itemConnectionResolver, error) {
+// PageInfo returns the page info of the User connection
+func (r *UserConnectionResolver) PageInfo(ctx context.Context) (*PageInfoResolver, error) {
+	return &PageInfoResolver{P: r.P}, nil
+}
 
-// }
+// TotalCount returns the number of users in the whole connection
+func (r *UserConnectionResolver) TotalCount(ctx context.Context) (int32, error) {
+	return r.Total, nil
+}
 
-// // TrendingConnection field represents a campaign connection
-// func (r *UserResolver) ScanConnection(ctx context.Context, args scanConnectionArgs) (*itemConnectionResolver, error) {
+// Cursor returns the cursor of the User edge
+func (r *UserEdgeResolver) Cursor(ctx context.Context) (string, error) {
+	return r.C, nil
+}
 
-// }
+// Node returns the user of the User edge
+func (r *UserEdgeResolver) Node(ctx context.Context) (*UserResolver, error) {
+	return r.N, nil
+}
 
-// type searchConnectionArgs struct {
-// 	connectionArgs
-// 	input string
-// }
+// FriendsConnection field represents the users this user is friends with, ordered by name
+func (r *UserResolver) FriendsConnection(ctx context.Context, args connectionArgs) (*UserConnectionResolver, error) {
+	id, err := r.U.rowID()
+	if err != nil {
+		return nil, err
+	}
+	page, err := connection.NewPage(connection.Args(args), connection.Order{Column: "name"})
+	if err != nil {
+		return nil, err
+	}
+	friends := Where("id in (select friend_id from friendship where usr_id = ?)", id)
+	total, err := models.UsrsG(friends).Count()
+	if err != nil {
+		return nil, err
+	}
+	usrs, err := models.UsrsG(append([]QueryMod{friends}, page.Mods()...)...).All()
+	if err != nil {
+		return nil, err
+	}
+	loaders := loadersFromContext(ctx)
+	var edges []*UserEdgeResolver
+	var cursors []string
+	for _, i := range page.Indexes(len(usrs)) {
+		usr := usrs[i]
+		loaders.Users.Prime(usr.ID, usr)
+		cursor := connection.Cursor{Value: usr.Name, ID: usr.ID}.Encode()
+		cursors = append(cursors, cursor)
+		edges = append(edges, &UserEdgeResolver{
+			C: cursor,
+			N: &UserResolver{V: r.V, U: userFromModel(usr)},
+		})
+	}
+	return &UserConnectionResolver{
+		E:     edges,
+		P:     page.PageInfo(cursors),
+		Total: int32(total),
+	}, nil
+}
@@ -0,0 +1,85 @@
+package gql
+
+import (
+	"context"
+
+	"github.com/go-ozzo/ozzo-validation"
+	"github.com/lib/pq"
+)
+
+// the codes of the user errors, the values of the UserErrorCode enum
+const (
+	userErrorRequired = "REQUIRED"
+	userErrorLength   = "LENGTH"
+	userErrorFormat   = "FORMAT"
+	userErrorTaken    = "TAKEN"
+)
+
+// emailUniqueIndex is the unique index on usr.email, violating it means the email is taken
+const emailUniqueIndex = "index_usr_on_email"
+
+// UserErrorResolver is what is wrong with an argument of a mutation
+type UserErrorResolver struct {
+	F string
+	C string
+	M string
+}
+
+// UserPayloadResolver is the payload of the mutations changing a user, the user is nil when there are user errors
+type UserPayloadResolver struct {
+	U *UserResolver
+	E []*UserErrorResolver
+}
+
+// fieldRule is a validation rule with the code reported when it fails
+type fieldRule struct {
+	code string
+	rule validation.Rule
+}
+
+// validateField runs the rules over the value of an argument in order, reporting the first one failing,
+// optional arguments left out are only checked by the REQUIRED rules
+func validateField(errs []*UserErrorResolver, field string, value interface{}, rules ...fieldRule) []*UserErrorResolver {
+	for _, r := range rules {
+		if err := validation.Validate(value, r.rule); err != nil {
+			return append(errs, &UserErrorResolver{F: field, C: r.code, M: err.Error()})
+		}
+	}
+	return errs
+}
+
+// emailTaken reports whether err is the violation of the unique email index, another request took the email first
+func emailTaken(err error) bool {
+	pqErr, ok := err.(*pq.Error)
+	return ok && pqErr.Code == "23505" && pqErr.Constraint == emailUniqueIndex
+}
+
+// takenEmail is the user error of an email another user has
+func takenEmail() *UserErrorResolver {
+	return &UserErrorResolver{F: "email", C: userErrorTaken, M: "is already taken"}
+}
+
+// User returns the user, null when the mutation was refused
+func (r *UserPayloadResolver) User(ctx context.Context) (*UserResolver, error) {
+	return r.U, nil
+}
+
+// UserErrors returns why the mutation was refused, empty when it succeeded
+func (r *UserPayloadResolver) UserErrors(ctx context.Context) ([]*UserErrorResolver, error) {
+	return r.E, nil
+}
+
+// Field returns the argument in error
+func (r *UserErrorResolver) Field(ctx context.Context) (*string, error) {
+	return &r.F, nil
+}
+
+// Code returns the kind of the error
+func (r *UserErrorResolver) Code(ctx context.Context) (string, error) {
+	return r.C, nil
+}
+
+// Message returns the error in words
+func (r *UserErrorResolver) Message(ctx context.Context) (string, error) {
+	return r.M, nil
+}
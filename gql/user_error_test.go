@@ -0,0 +1,44 @@
+package gql
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/go-ozzo/ozzo-validation"
+	"github.com/go-ozzo/ozzo-validation/is"
+	"github.com/lib/pq"
+)
+
+func TestUserErrors(t *testing.T) {
+	t.Run("report the first failing rule", func(t *testing.T) {
+		for _, c := range []struct {
+			value string
+			code  string
+		}{
+			{"", userErrorRequired},
+			{"a@b", userErrorLength},
+			{"not an email", userErrorFormat},
+		} {
+			errs := validateField(nil, "email", c.value,
+				fieldRule{userErrorRequired, validation.Required},
+				fieldRule{userErrorLength, validation.Length(5, 50)},
+				fieldRule{userErrorFormat, is.Email},
+			)
+			if len(errs) != 1 || errs[0].F != "email" || errs[0].C != c.code {
+				t.Errorf("expected %s for %q, got %+v", c.code, c.value, errs)
+			}
+		}
+	})
+
+	t.Run("recognize the unique email violation", func(t *testing.T) {
+		if !emailTaken(&pq.Error{Code: "23505", Constraint: emailUniqueIndex}) {
+			t.Errorf("expected the email to be taken")
+		}
+		if emailTaken(&pq.Error{Code: "23505", Constraint: "usr_pkey"}) || emailTaken(errors.New("other")) {
+			t.Errorf("expected only the email index to mean taken")
+		}
+		if e := takenEmail(); e.F != "email" || e.C != userErrorTaken {
+			t.Errorf("unexpected user error %+v", e)
+		}
+	})
+}
@@ -9,10 +9,10 @@ import (
 	"io/ioutil"
 	"testing"
 
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/gqltesting"
 	_ "github.com/lib/pq"
 	"github.com/malisit/kolpa"
-	graphql "github.com/neelance/graphql-go"
-	"github.com/neelance/graphql-go/gqltesting"
 	"github.com/tidwall/gjson"
 	"github.com/volatiletech/sqlboiler/boil"
 )
@@ -38,13 +38,15 @@ func TestUserResolvers(t *testing.T) {
 				Query: `
 					mutation {
 						signup(name: "` + name + `", email: "` + email + `", password: "` + password + `") {
-							name
-							email
+							user {
+								name
+								email
+							}
 						}
 					}
 				`,
 				ExpectedResult: `
-					{"signup":{"email":"` + email + `","name":"` + name + `"}}
+					{"signup":{"user":{"email":"` + email + `","name":"` + name + `"}}}
 				`,
 			},
 		})
@@ -99,16 +101,94 @@ func TestUserResolvers(t *testing.T) {
 			Query: `
 			mutation {
 				signup(name: "` + name + `", email: "` + email + `", password: "` + password + `") {
-					name
-					email
+					user {
+						name
+						email
+					}
+					userErrors {
+						field
+						code
+						message
+					}
 				}
 			}
 		`,
 		}
 		result, _ := json.Marshal(test.Schema.Exec(context.Background(), test.Query, test.OperationName, test.Variables))
-		err := gjson.ParseBytes(result).Get("errors.0.message").String()
-		if err != "Email: must be a valid email address." {
-			t.Errorf("expected error")
+		payload := gjson.ParseBytes(result).Get("data.signup")
+		userError := payload.Get("userErrors.0")
+		if payload.Get("user").Type != gjson.Null || userError.Get("field").String() != "email" ||
+			userError.Get("code").String() != "FORMAT" || userError.Get("message").String() != "must be a valid email address" {
+			t.Errorf("expected an email user error, got %s", payload.Raw)
+		}
+	})
+
+	t.Run("reject taken email", func(t *testing.T) {
+		t.Parallel()
+		email := fake.Email()
+		password := fake.LoremSentence()
+		name := fake.Name()
+		query := `
+			mutation {
+				signup(name: "` + name + `", email: "` + email + `", password: "` + password + `") {
+					user {
+						email
+					}
+					userErrors {
+						field
+						code
+						message
+					}
+				}
+			}
+		`
+		first, _ := json.Marshal(schema.Exec(context.Background(), query, "", nil))
+		if gjson.ParseBytes(first).Get("data.signup.user.email").String() != email {
+			t.Fatalf("expected the first signup to succeed, got %s", first)
+		}
+		result, _ := json.Marshal(schema.Exec(context.Background(), query, "", nil))
+		payload := gjson.ParseBytes(result).Get("data.signup")
+		userError := payload.Get("userErrors.0")
+		if payload.Get("user").Type != gjson.Null || userError.Get("field").String() != "email" ||
+			userError.Get("code").String() != "TAKEN" || userError.Get("message").String() != "is already taken" {
+			t.Errorf("expected a taken email user error, got %s", payload.Raw)
+		}
+		// every invalid argument comes back at once
+		short := `mutation { signup(name: "` + name + `", email: "` + email + `", password: "abc") { userErrors { field code } } }`
+		result, _ = json.Marshal(schema.Exec(context.Background(), short, "", nil))
+		userErrors := gjson.ParseBytes(result).Get("data.signup.userErrors")
+		if userErrors.Raw != `[{"field":"email","code":"TAKEN"},{"field":"password","code":"LENGTH"}]` {
+			t.Errorf("expected the taken email and the short password together, got %s", userErrors.Raw)
+		}
+	})
+
+	t.Run("reject short name", func(t *testing.T) {
+		t.Parallel()
+		email := fake.Email()
+		password := fake.LoremSentence()
+		test := gqltesting.Test{
+			Schema: schema,
+			Query: `
+			mutation {
+				signup(name: "Bob", email: "` + email + `", password: "` + password + `") {
+					user {
+						name
+					}
+					userErrors {
+						field
+						code
+						message
+					}
+				}
+			}
+		`,
+		}
+		result, _ := json.Marshal(test.Schema.Exec(context.Background(), test.Query, test.OperationName, test.Variables))
+		payload := gjson.ParseBytes(result).Get("data.signup")
+		userError := payload.Get("userErrors.0")
+		if payload.Get("user").Type != gjson.Null || userError.Get("field").String() != "name" ||
+			userError.Get("code").String() != "LENGTH" || userError.Get("message").String() != "the length must be between 5 and 50" {
+			t.Errorf("expected a name length user error, got %s", payload.Raw)
 		}
 	})
 
@@ -125,13 +205,15 @@ func TestUserResolvers(t *testing.T) {
 				Query: `
 					mutation {
 						signup(name: "` + name + `", email: "` + email + `", password: "` + password + `") {
-							name
-							email
+							user {
+								name
+								email
+							}
 						}
 					}
 				`,
 				ExpectedResult: `
-					{"signup":{"email":"` + email + `","name":"` + name + `"}}
+					{"signup":{"user":{"email":"` + email + `","name":"` + name + `"}}}
 				`,
 			},
 		})
@@ -190,13 +272,15 @@ func TestUserResolvers(t *testing.T) {
 				Query: `
 					mutation {
 						signup(name: "` + name + `", email: "` + email + `", password: "` + password + `") {
-							name
-							email
+							user {
+								name
+								email
+							}
 						}
 					}
 				`,
 				ExpectedResult: `
-					{"signup":{"email":"` + email + `","name":"` + name + `"}}
+					{"signup":{"user":{"email":"` + email + `","name":"` + name + `"}}}
 				`,
 			},
 		})
@@ -216,13 +300,15 @@ func TestUserResolvers(t *testing.T) {
 				Query: `
 				mutation {
 					updateUser(jwt: "` + jwt + `", email: "` + email2 + `", password: "` + password2 + `") {
-						name
-						email
+						user {
+							name
+							email
+						}
 					}
 				}
 			`,
 				ExpectedResult: `
-				{"updateUser":{"email":"` + email2 + `","name":"` + name + `"}}
+				{"updateUser":{"user":{"email":"` + email2 + `","name":"` + name + `"}}}
 			`,
 			},
 		})
@@ -265,13 +351,15 @@ func TestUserResolvers(t *testing.T) {
 				Query: `
 					mutation {
 						signup(name: "` + name + `", email: "` + email + `", password: "` + password + `") {
-							name
-							email
+							user {
+								name
+								email
+							}
 						}
 					}
 				`,
 				ExpectedResult: `
-					{"signup":{"email":"` + email + `","name":"` + name + `"}}
+					{"signup":{"user":{"email":"` + email + `","name":"` + name + `"}}}
 				`,
 			},
 		})
@@ -291,13 +379,15 @@ func TestUserResolvers(t *testing.T) {
 				Query: `
 				mutation {
 					updateUser(jwt: "` + jwt + `", email: "` + email2 + `") {
-						name
-						email
+						user {
+							name
+							email
+						}
 					}
 				}
 			`,
 				ExpectedResult: `
-				{"updateUser":{"email":"` + email2 + `","name":"` + name + `"}}
+				{"updateUser":{"user":{"email":"` + email2 + `","name":"` + name + `"}}}
 			`,
 			},
 		})
@@ -341,13 +431,15 @@ func TestUserResolvers(t *testing.T) {
 				Query: `
 					mutation {
 						signup(name: "` + name + `", email: "` + email + `", password: "` + password + `") {
-							name
-							email
+							user {
+								name
+								email
+							}
 						}
 					}
 				`,
 				ExpectedResult: `
-					{"signup":{"email":"` + email + `","name":"` + name + `"}}
+					{"signup":{"user":{"email":"` + email + `","name":"` + name + `"}}}
 				`,
 			},
 		})
@@ -366,16 +458,25 @@ func TestUserResolvers(t *testing.T) {
 			Query: `
 				mutation {
 					updateUser(jwt: "` + jwt + `", email: "` + email2 + `") {
-						name
-						email
+						user {
+							name
+							email
+						}
+						userErrors {
+							field
+							code
+							message
+						}
 					}
 				}
 			`,
 		}
 		result2, _ := json.Marshal(test2.Schema.Exec(context.Background(), test2.Query, test2.OperationName, test2.Variables))
-		err := gjson.ParseBytes(result2).Get("errors.0.message").String()
-		if err != "Email: must be a valid email address." {
-			t.Errorf("expected error")
+		payload := gjson.ParseBytes(result2).Get("data.updateUser")
+		userError := payload.Get("userErrors.0")
+		if payload.Get("user").Type != gjson.Null || userError.Get("field").String() != "email" ||
+			userError.Get("code").String() != "FORMAT" || userError.Get("message").String() != "must be a valid email address" {
+			t.Errorf("expected an email user error, got %s", payload.Raw)
 		}
 	})
 
@@ -391,13 +492,15 @@ func TestUserResolvers(t *testing.T) {
 				Query: `
 					mutation {
 						signup(name: "` + name + `", email: "` + email + `", password: "` + password + `") {
-							name
-							email
+							user {
+								name
+								email
+							}
 						}
 					}
 				`,
 				ExpectedResult: `
-					{"signup":{"email":"` + email + `","name":"` + name + `"}}
+					{"signup":{"user":{"email":"` + email + `","name":"` + name + `"}}}
 				`,
 			},
 		})
@@ -417,13 +520,15 @@ func TestUserResolvers(t *testing.T) {
 				Query: `
 				mutation {
 					updateUser(jwt: "` + jwt + `", name: "` + name2 + `") {
-						name
-						email
+						user {
+							name
+							email
+						}
 					}
 				}
 			`,
 				ExpectedResult: `
-				{"updateUser":{"email":"` + email + `","name":"` + name2 + `"}}
+				{"updateUser":{"user":{"email":"` + email + `","name":"` + name2 + `"}}}
 			`,
 			},
 		})
@@ -466,13 +571,15 @@ func TestUserResolvers(t *testing.T) {
 				Query: `
 					mutation {
 						signup(name: "` + name + `", email: "` + email + `", password: "` + password + `") {
-							name
-							email
+							user {
+								name
+								email
+							}
 						}
 					}
 				`,
 				ExpectedResult: `
-					{"signup":{"email":"` + email + `","name":"` + name + `"}}
+					{"signup":{"user":{"email":"` + email + `","name":"` + name + `"}}}
 				`,
 			},
 		})
@@ -492,13 +599,15 @@ func TestUserResolvers(t *testing.T) {
 				Query: `
 				mutation {
 					updateUser(jwt: "` + jwt + `", password: "` + password + `", email: "` + email + `", name: "` + name + `") {
-						name
-						email
+						user {
+							name
+							email
+						}
 					}
 				}
 			`,
 				ExpectedResult: `
-				{"updateUser":{"email":"` + email + `","name":"` + name + `"}}
+				{"updateUser":{"user":{"email":"` + email + `","name":"` + name + `"}}}
 			`,
 			},
 		})
@@ -542,13 +651,15 @@ func TestUserResolvers(t *testing.T) {
 				Query: `
 					mutation {
 						signup(name: "` + name + `", email: "` + email + `", password: "` + password + `") {
-							name
-							email
+							user {
+								name
+								email
+							}
 						}
 					}
 				`,
 				ExpectedResult: `
-					{"signup":{"email":"` + email + `","name":"` + name + `"}}
+					{"signup":{"user":{"email":"` + email + `","name":"` + name + `"}}}
 				`,
 			},
 		})
@@ -568,13 +679,15 @@ func TestUserResolvers(t *testing.T) {
 				Query: `
 				mutation {
 					updateUser(jwt: "` + jwt + `", password: "` + password2 + `") {
-						name
-						email
+						user {
+							name
+							email
+						}
 					}
 				}
 			`,
 				ExpectedResult: `
-				{"updateUser":{"email":"` + email + `","name":"` + name + `"}}
+				{"updateUser":{"user":{"email":"` + email + `","name":"` + name + `"}}}
 			`,
 			},
 		})
@@ -604,6 +717,41 @@ func TestUserResolvers(t *testing.T) {
 			`,
 			},
 		})
+		exec := func(query string) gjson.Result {
+			result, _ := json.Marshal(schema.Exec(context.Background(), query, "", nil))
+			return gjson.ParseBytes(result)
+		}
+		signedIn := func(token string) bool {
+			result := exec(`{ viewer(jwt: "` + token + `") { email } }`)
+			return result.Get("data.viewer.email").String() == email && !result.Get("errors").Exists()
+		}
+		login := func() string {
+			return exec(`{ jwt(email: "` + email + `", password: "` + password2 + `") }`).Get("data.jwt").String()
+		}
+		if signedIn(jwt) {
+			t.Errorf("expected the token issued before the password change to be rejected")
+		}
+
+		jwt3 := login()
+		if !exec(`mutation { logout(jwt: "` + jwt2 + `") }`).Get("data.logout").Bool() {
+			t.Fatalf("expected logout to succeed")
+		}
+		if signedIn(jwt2) {
+			t.Errorf("expected the token logged out to be rejected")
+		}
+		if !signedIn(jwt3) {
+			t.Errorf("expected logout to keep the other sessions")
+		}
+
+		if !exec(`mutation { logoutAllSessions(jwt: "` + jwt3 + `") }`).Get("data.logoutAllSessions").Bool() {
+			t.Fatalf("expected logoutAllSessions to succeed")
+		}
+		if signedIn(jwt3) {
+			t.Errorf("expected every session to be logged out")
+		}
+		if !signedIn(login()) {
+			t.Errorf("expected a new session to work after logoutAllSessions")
+		}
 	})
 
 	t.Run("user creation", func(t *testing.T) {
@@ -618,13 +766,15 @@ func TestUserResolvers(t *testing.T) {
 				Query: `
 					mutation {
 						signup(name: "` + name + `", email: "` + email + `", password: "` + password + `") {
-							name
-							email
+							user {
+								name
+								email
+							}
 						}
 					}
 				`,
 				ExpectedResult: `
-					{"signup":{"email":"` + email + `","name":"` + name + `"}}
+					{"signup":{"user":{"email":"` + email + `","name":"` + name + `"}}}
 				`,
 			},
 		})
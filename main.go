@@ -11,17 +11,24 @@ import (
 
 	"go-lambda-graphql/config"
 	"go-lambda-graphql/gql"
+	"go-lambda-graphql/services/auth"
+	"go-lambda-graphql/services/gateway"
+	"go-lambda-graphql/services/graphqlhttp"
+	"go-lambda-graphql/services/graphqlws"
+	"go-lambda-graphql/services/notify"
+	"go-lambda-graphql/services/persisted"
 
+	"github.com/graph-gophers/graphql-go"
 	"github.com/julienschmidt/httprouter"
 	_ "github.com/lib/pq"
-	"github.com/neelance/graphql-go"
-	"github.com/neelance/graphql-go/relay"
 	"github.com/volatiletech/sqlboiler/boil"
 	"xi2.org/x/httpgzip"
 )
 
 var schema *graphql.Schema
 
+var persistedQueries persisted.Store
+
 func checkPanicError(err error) {
 	if err != nil {
 		fmt.Println(err)
@@ -33,20 +40,61 @@ func init() {
 	// opentracing.SetGlobalTracer(graphql.Tracer)
 	rawSchema, err := ioutil.ReadFile("gql/schema.gql")
 	checkPanicError(err)
-	schema = graphql.MustParseSchema(string(rawSchema), &gql.Resolver{})
+	schema = gql.MustParseSchema(string(rawSchema))
 	boil.DebugMode = !config.IsProduction
 	db, err := sql.Open("postgres", config.ConnectionString)
 	checkPanicError(err)
 	boil.SetDB(db)
+	persistedQueries = &persisted.SQLStore{DB: db}
+	if config.PersistedQueriesDir != "" {
+		// lambda cannot write its file system, the shipped queries are served as they are
+		persistedQueries = &persisted.FileStore{Dir: config.PersistedQueriesDir, ReadOnly: config.IsLambda}
+	}
+	// api gateway cannot carry websockets, only the standalone server serves subscriptions
+	if !config.IsLambda {
+		listener, err := notify.NewListener(config.ConnectionString, gql.NotificationChannels...)
+		checkPanicError(err)
+		gql.UseNotifications(listener)
+	}
 }
 
-func main() {
+func newRouter() *httprouter.Router {
 	router := httprouter.New()
 
 	// routes
-	router.Handler("POST", "/query", httpgzip.NewHandler(&relay.Handler{Schema: schema}, nil))
+	query := httpgzip.NewHandler(gql.AttachLoaders(gql.Authenticate(&graphqlhttp.Handler{
+		Schema:     schema,
+		Check:      gql.CheckQuery,
+		Queries:    persistedQueries,
+		Register:   gql.RegistersQueries,
+		MaxAge:     config.QueryCacheMaxAge,
+		Production: config.IsProduction,
+	})), nil)
+	router.Handler("POST", "/query", query)
+	router.Handler("GET", "/query", query)
+	if !config.IsLambda {
+		router.Handler("GET", "/subscriptions", gql.AttachLoaders(gql.Authenticate(&graphqlws.Handler{
+			Schema:     schema,
+			Init:       gql.InitSubscription,
+			Check:      gql.CheckQuery,
+			Production: config.IsProduction,
+		})))
+	}
+	router.Handler("GET", "/.well-known/jwks.json", http.HandlerFunc(auth.JWKSHandler))
 	router.NotFound = httpgzip.NewHandler(http.FileServer(http.Dir(config.Directory)), nil).ServeHTTP
 
+	return router
+}
+
+func main() {
+	router := newRouter()
+
+	if config.IsLambda {
+		fmt.Println("go server listening for api gateway events")
+		gateway.ListenAndServe(router)
+		return
+	}
+
 	s := &http.Server{
 		Addr:           ":" + config.Port,
 		Handler:        router,
@@ -0,0 +1,23 @@
+-- +migrate Up
+-- +migrate StatementBegin
+
+CREATE TABLE refresh_token (
+    id bigserial PRIMARY KEY,
+    usr_id bigint NOT NULL REFERENCES usr (id) ON DELETE CASCADE,
+    token_hash text NOT NULL,
+    family text NOT NULL,
+    expires_at timestamp without time zone NOT NULL,
+    used_at timestamp without time zone,
+    revoked_at timestamp without time zone,
+    created_at timestamp without time zone NOT NULL DEFAULT now(),
+    updated_at timestamp without time zone NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX index_refresh_token_on_token_hash ON refresh_token USING btree (token_hash);
+
+CREATE INDEX index_refresh_token_on_family ON refresh_token USING btree (family);
+
+-- +migrate StatementEnd
+
+-- +migrate Down
+DROP TABLE IF EXISTS refresh_token CASCADE;
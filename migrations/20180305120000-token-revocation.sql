@@ -0,0 +1,17 @@
+-- +migrate Up
+-- +migrate StatementBegin
+
+ALTER TABLE usr ADD COLUMN token_version integer NOT NULL DEFAULT 0;
+
+CREATE TABLE revoked_token (
+    jti text PRIMARY KEY,
+    expires_at timestamp without time zone NOT NULL
+);
+
+CREATE INDEX index_revoked_token_on_expires_at ON revoked_token USING btree (expires_at);
+
+-- +migrate StatementEnd
+
+-- +migrate Down
+DROP TABLE IF EXISTS revoked_token CASCADE;
+ALTER TABLE usr DROP COLUMN IF EXISTS token_version;
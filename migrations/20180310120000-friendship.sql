@@ -0,0 +1,16 @@
+-- +migrate Up
+-- +migrate StatementBegin
+
+CREATE TABLE friendship (
+    usr_id bigint NOT NULL REFERENCES usr (id) ON DELETE CASCADE,
+    friend_id bigint NOT NULL REFERENCES usr (id) ON DELETE CASCADE,
+    created_at timestamp without time zone NOT NULL DEFAULT now(),
+    PRIMARY KEY (usr_id, friend_id)
+);
+
+CREATE INDEX index_friendship_on_friend_id ON friendship USING btree (friend_id);
+
+-- +migrate StatementEnd
+
+-- +migrate Down
+DROP TABLE IF EXISTS friendship CASCADE;
@@ -0,0 +1,14 @@
+-- +migrate Up
+-- +migrate StatementBegin
+
+CREATE SEQUENCE submittal_log_facts_id_seq OWNED BY submittal_log_facts.id;
+
+SELECT setval('submittal_log_facts_id_seq', coalesce(max(id), 0) + 1, false) FROM submittal_log_facts;
+
+ALTER TABLE submittal_log_facts ALTER COLUMN id SET DEFAULT nextval('submittal_log_facts_id_seq');
+
+-- +migrate StatementEnd
+
+-- +migrate Down
+ALTER TABLE submittal_log_facts ALTER COLUMN id DROP DEFAULT;
+DROP SEQUENCE IF EXISTS submittal_log_facts_id_seq;
@@ -0,0 +1,9 @@
+-- +migrate Up
+-- +migrate StatementBegin
+
+CREATE INDEX index_submittal_log_facts_on_deleted_at ON submittal_log_facts USING btree (deleted_at);
+
+-- +migrate StatementEnd
+
+-- +migrate Down
+DROP INDEX IF EXISTS index_submittal_log_facts_on_deleted_at;
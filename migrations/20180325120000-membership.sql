@@ -0,0 +1,23 @@
+-- +migrate Up
+-- +migrate StatementBegin
+
+CREATE TABLE membership (
+    id bigserial PRIMARY KEY,
+    usr_id bigint NOT NULL REFERENCES usr (id) ON DELETE CASCADE,
+    company_id bigint NOT NULL,
+    project_id bigint,
+    role text NOT NULL DEFAULT 'member' CHECK (role IN ('member', 'manager')),
+    source_user_id bigint,
+    created_at timestamp without time zone NOT NULL DEFAULT now(),
+    updated_at timestamp without time zone NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX index_membership_on_usr_id_company_id_project_id ON membership USING btree (usr_id, company_id, coalesce(project_id, 0));
+
+CREATE INDEX index_submittal_log_facts_on_company_id_project_id ON submittal_log_facts USING btree (company_id, project_id);
+
+-- +migrate StatementEnd
+
+-- +migrate Down
+DROP INDEX IF EXISTS index_submittal_log_facts_on_company_id_project_id;
+DROP TABLE IF EXISTS membership CASCADE;
@@ -0,0 +1,40 @@
+-- +migrate Up
+-- +migrate StatementBegin
+
+CREATE TABLE role (
+    name text PRIMARY KEY,
+    description text NOT NULL DEFAULT ''
+);
+
+CREATE TABLE role_permission (
+    role text NOT NULL REFERENCES role (name) ON DELETE CASCADE ON UPDATE CASCADE,
+    permission text NOT NULL,
+    PRIMARY KEY (role, permission)
+);
+
+CREATE TABLE usr_role (
+    usr_id bigint NOT NULL REFERENCES usr (id) ON DELETE CASCADE,
+    role text NOT NULL REFERENCES role (name) ON DELETE CASCADE ON UPDATE CASCADE,
+    created_at timestamp without time zone NOT NULL DEFAULT now(),
+    PRIMARY KEY (usr_id, role)
+);
+
+INSERT INTO role (name, description) VALUES
+    ('admin', 'manages roles, memberships and every submittal'),
+    ('auditor', 'reads every submittal, including the deleted ones');
+
+INSERT INTO role_permission (role, permission) VALUES
+    ('admin', 'manage_roles'),
+    ('admin', 'manage_memberships'),
+    ('admin', 'manage_submittals'),
+    ('admin', 'read_all_submittals'),
+    ('admin', 'read_deleted_submittals'),
+    ('auditor', 'read_all_submittals'),
+    ('auditor', 'read_deleted_submittals');
+
+-- +migrate StatementEnd
+
+-- +migrate Down
+DROP TABLE IF EXISTS usr_role CASCADE;
+DROP TABLE IF EXISTS role_permission CASCADE;
+DROP TABLE IF EXISTS role CASCADE;
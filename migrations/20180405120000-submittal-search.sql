@@ -0,0 +1,30 @@
+-- +migrate Up
+-- +migrate StatementBegin
+
+ALTER TABLE submittal_log_facts ADD COLUMN search_vector tsvector;
+
+CREATE FUNCTION submittal_log_facts_search_vector() RETURNS trigger AS $$
+BEGIN
+    NEW.search_vector :=
+        setweight(to_tsvector('simple', concat_ws(' ', NEW.number, NEW.package_number, NEW.spec_section_number, NEW.project_number)), 'A') ||
+        setweight(to_tsvector('english', coalesce(NEW.title, '')), 'A') ||
+        setweight(to_tsvector('english', concat_ws(' ', NEW.spec_section_description, NEW.package_name, NEW.project_name)), 'B') ||
+        setweight(to_tsvector('english', coalesce(NEW.description, '')), 'C');
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+-- postgres 9.5 has no generated columns, the trigger keeps search_vector in sync instead
+CREATE TRIGGER submittal_log_facts_search_vector BEFORE INSERT OR UPDATE ON submittal_log_facts
+    FOR EACH ROW EXECUTE PROCEDURE submittal_log_facts_search_vector();
+
+UPDATE submittal_log_facts SET id = id;
+
+CREATE INDEX index_submittal_log_facts_on_search_vector ON submittal_log_facts USING gin (search_vector);
+
+-- +migrate StatementEnd
+
+-- +migrate Down
+DROP TRIGGER IF EXISTS submittal_log_facts_search_vector ON submittal_log_facts;
+DROP FUNCTION IF EXISTS submittal_log_facts_search_vector();
+ALTER TABLE submittal_log_facts DROP COLUMN IF EXISTS search_vector;
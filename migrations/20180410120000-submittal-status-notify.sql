@@ -0,0 +1,19 @@
+-- +migrate Up
+-- +migrate StatementBegin
+
+-- feeds the submittalStatusChanged subscriptions, the payload stays small, the subscribers reload the row
+CREATE FUNCTION submittal_log_facts_status_notify() RETURNS trigger AS $$
+BEGIN
+    PERFORM pg_notify('submittal_status', json_build_object('id', NEW.id, 'status', NEW.status, 'previous_status', OLD.status)::text);
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER submittal_log_facts_status_notify AFTER UPDATE OF status ON submittal_log_facts
+    FOR EACH ROW WHEN (OLD.status IS DISTINCT FROM NEW.status) EXECUTE PROCEDURE submittal_log_facts_status_notify();
+
+-- +migrate StatementEnd
+
+-- +migrate Down
+DROP TRIGGER IF EXISTS submittal_log_facts_status_notify ON submittal_log_facts;
+DROP FUNCTION IF EXISTS submittal_log_facts_status_notify();
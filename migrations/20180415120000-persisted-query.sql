@@ -0,0 +1,13 @@
+-- +migrate Up
+-- +migrate StatementBegin
+
+CREATE TABLE persisted_query (
+    hash text PRIMARY KEY,
+    query text NOT NULL,
+    created_at timestamp without time zone NOT NULL DEFAULT now()
+);
+
+-- +migrate StatementEnd
+
+-- +migrate Down
+DROP TABLE IF EXISTS persisted_query;
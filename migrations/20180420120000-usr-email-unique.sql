@@ -0,0 +1,23 @@
+-- +migrate Up
+-- +migrate StatementBegin
+
+-- users sharing an email have to be merged or renamed by hand first, they are listed rather than deleted
+DO $$
+DECLARE
+    duplicates text;
+BEGIN
+    SELECT string_agg(email || ' (' || ids || ')', ', ') INTO duplicates
+    FROM (SELECT email, string_agg(id::text, ', ' ORDER BY id) AS ids FROM usr GROUP BY email HAVING count(*) > 1) d;
+    IF duplicates IS NOT NULL THEN
+        RAISE EXCEPTION 'usr.email is not unique, resolve these emails before migrating: %', duplicates;
+    END IF;
+END
+$$;
+
+-- signup and updateUser check the email first, the index settles two requests racing for the same one
+CREATE UNIQUE INDEX index_usr_on_email ON usr USING btree (email);
+
+-- +migrate StatementEnd
+
+-- +migrate Down
+DROP INDEX IF EXISTS index_usr_on_email;
@@ -0,0 +1,42 @@
+-- +migrate Up
+-- +migrate StatementBegin
+
+CREATE TABLE item (
+    id bigserial PRIMARY KEY,
+    address text NOT NULL DEFAULT '',
+    geohash text NOT NULL DEFAULT '',
+    created_at timestamp without time zone NOT NULL DEFAULT now(),
+    updated_at timestamp without time zone NOT NULL DEFAULT now()
+);
+
+CREATE INDEX index_item_on_geohash ON item USING btree (geohash text_pattern_ops);
+
+CREATE TABLE item_name (
+    id bigserial PRIMARY KEY,
+    item_id bigint NOT NULL REFERENCES item (id) ON DELETE CASCADE,
+    name text NOT NULL,
+    created_at timestamp without time zone NOT NULL DEFAULT now(),
+    updated_at timestamp without time zone NOT NULL DEFAULT now()
+);
+
+CREATE INDEX index_item_name_on_item_id ON item_name USING btree (item_id);
+
+CREATE TABLE campaign (
+    id bigserial PRIMARY KEY,
+    item_id bigint NOT NULL REFERENCES item (id) ON DELETE CASCADE,
+    funded double precision NOT NULL DEFAULT 0,
+    needed double precision NOT NULL CHECK (needed > 0),
+    result text NOT NULL DEFAULT '',
+    status double precision NOT NULL DEFAULT 0,
+    created_at timestamp without time zone NOT NULL DEFAULT now(),
+    updated_at timestamp without time zone NOT NULL DEFAULT now()
+);
+
+CREATE INDEX index_campaign_on_item_id ON campaign USING btree (item_id);
+
+-- +migrate StatementEnd
+
+-- +migrate Down
+DROP TABLE IF EXISTS campaign;
+DROP TABLE IF EXISTS item_name;
+DROP TABLE IF EXISTS item;
@@ -0,0 +1,106 @@
+package apperr
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+
+	"github.com/go-ozzo/ozzo-validation"
+	"github.com/graph-gophers/graphql-go/errors"
+)
+
+// Code tells clients what went wrong, it is sent in the extensions of the error
+type Code string
+
+// the codes of the errors
+const (
+	Unauthenticated  Code = "UNAUTHENTICATED"
+	Forbidden        Code = "FORBIDDEN"
+	ValidationFailed Code = "VALIDATION_FAILED"
+	NotFound         Code = "NOT_FOUND"
+	Conflict         Code = "CONFLICT"
+	Internal         Code = "INTERNAL"
+)
+
+// internalMessage replaces the message of the internal errors in production
+const internalMessage = "internal error"
+
+// Error is an error whose message can be shown to clients
+type Error struct {
+	Code    Code
+	Message string
+	// Fields maps the invalid arguments to what is wrong with them
+	Fields map[string]string
+}
+
+// New returns an error with code
+func New(code Code, message string) *Error {
+	return &Error{Code: code, Message: message}
+}
+
+// Errorf returns an error with code and a formatted message
+func Errorf(code Code, format string, a ...interface{}) *Error {
+	return &Error{Code: code, Message: fmt.Sprintf(format, a...)}
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Extensions implements the extensions of graphql-go resolver errors
+func (e *Error) Extensions() map[string]interface{} {
+	extensions := map[string]interface{}{"code": e.Code}
+	if len(e.Fields) > 0 {
+		extensions["fields"] = e.Fields
+	}
+	return extensions
+}
+
+// Validation turns the errors of validation.ValidateStruct into a VALIDATION_FAILED error listing the invalid fields,
+// other errors are returned as is
+func Validation(err error) error {
+	errs, ok := err.(validation.Errors)
+	if !ok {
+		return err
+	}
+	fields := make(map[string]string, len(errs))
+	for field, fieldErr := range errs {
+		// the struct fields of the arguments are the graphql arguments capitalized
+		fields[strings.ToLower(field[:1])+field[1:]] = fieldErr.Error()
+	}
+	return &Error{Code: ValidationFailed, Message: errs.Error(), Fields: fields}
+}
+
+var validCorrelationID = regexp.MustCompile(`^[\w-]{1,64}$`)
+
+// CorrelationID returns the request id the client or a proxy sent, or a new random one
+func CorrelationID(requestID string) string {
+	if validCorrelationID.MatchString(requestID) {
+		return requestID
+	}
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// Mask makes the errors of a response safe to show, errors other than *Error come from the database
+// or a bug, they are logged with the correlation id and become INTERNAL errors, without their message in production
+func Mask(errs []*errors.QueryError, correlationID string, production bool) {
+	for _, e := range errs {
+		if _, ok := e.ResolverError.(*Error); ok {
+			continue
+		}
+		// the other errors without a resolver error come from the validation of the query, except panics
+		if e.ResolverError == nil && !strings.HasPrefix(e.Message, "graphql: panic occurred") {
+			continue
+		}
+		log.Printf("%s: %v at %v", correlationID, e.Message, e.Path)
+		if production {
+			e.Message = internalMessage
+		}
+		e.Extensions = map[string]interface{}{"code": Internal, "correlationId": correlationID}
+	}
+}
@@ -0,0 +1,73 @@
+package apperr
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+
+	"github.com/go-ozzo/ozzo-validation"
+	"github.com/graph-gophers/graphql-go/errors"
+)
+
+func TestValidation(t *testing.T) {
+	args := struct {
+		Email string
+		Name  string
+	}{Email: "a", Name: "bob smith"}
+	err := Validation(validation.ValidateStruct(&args,
+		validation.Field(&args.Email, validation.Length(5, 50)),
+		validation.Field(&args.Name, validation.Length(5, 50)),
+	))
+	e, ok := err.(*Error)
+	if !ok || e.Code != ValidationFailed {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if _, ok := e.Fields["email"]; !ok || len(e.Fields) != 1 {
+		t.Errorf("unexpected fields %v", e.Fields)
+	}
+	if ext := e.Extensions(); ext["code"] != ValidationFailed || !reflect.DeepEqual(ext["fields"], e.Fields) {
+		t.Errorf("unexpected extensions %v", ext)
+	}
+	if Validation(sql.ErrNoRows) != sql.ErrNoRows {
+		t.Errorf("expected other errors as is")
+	}
+}
+
+func TestMask(t *testing.T) {
+	forbidden := New(Forbidden, "forbidden")
+	errs := []*errors.QueryError{
+		{Message: "forbidden", ResolverError: forbidden, Extensions: forbidden.Extensions()},
+		{Message: "pq: relation does not exist", ResolverError: sql.ErrConnDone},
+		{Message: "graphql: panic occurred: nil pointer"},
+		{Message: "Cannot query field \"foo\" on type \"Query\"."},
+	}
+	Mask(errs, "abc", true)
+	if errs[0].Message != "forbidden" || errs[0].Extensions["code"] != Forbidden {
+		t.Errorf("unexpected typed error %+v", errs[0])
+	}
+	for _, e := range errs[1:3] {
+		if e.Message != internalMessage || e.Extensions["code"] != Internal || e.Extensions["correlationId"] != "abc" {
+			t.Errorf("unexpected masked error %+v", e)
+		}
+	}
+	if errs[3].Extensions != nil {
+		t.Errorf("unexpected validation error %+v", errs[3])
+	}
+
+	t.Run("show messages outside production", func(t *testing.T) {
+		errs := []*errors.QueryError{{Message: "pq: relation does not exist", ResolverError: sql.ErrConnDone}}
+		Mask(errs, "abc", false)
+		if errs[0].Message != "pq: relation does not exist" || errs[0].Extensions["code"] != Internal {
+			t.Errorf("unexpected error %+v", errs[0])
+		}
+	})
+}
+
+func TestCorrelationID(t *testing.T) {
+	if id := CorrelationID("req-1"); id != "req-1" {
+		t.Errorf("unexpected id %s", id)
+	}
+	if a, b := CorrelationID("bad id\n"), CorrelationID(""); len(a) != 32 || len(b) != 32 || a == b {
+		t.Errorf("unexpected ids %s %s", a, b)
+	}
+}
@@ -1,8 +1,14 @@
 package auth
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"go-lambda-graphql/config"
+	"go-lambda-graphql/services/generate"
+	"go-lambda-graphql/services/keys"
+	"net/http"
+	"time"
 
 	jwt "github.com/dgrijalva/jwt-go"
 	"golang.org/x/crypto/bcrypt"
@@ -10,11 +16,28 @@ import (
 
 var hashCost int
 
+// Keys holds the jwt signing keys
+var Keys *keys.Set
+
 func init() {
 	hashCost = 1
 	if config.IsProduction {
 		hashCost = bcrypt.DefaultCost
 	}
+	var err error
+	switch {
+	case config.JWTKeys != "":
+		Keys, err = keys.Parse([]byte(config.JWTKeys))
+	case config.JWTKeysFile != "":
+		Keys, err = keys.ParseFile(config.JWTKeysFile)
+	case config.IsProduction:
+		err = errors.New("JWT_KEYS or JWT_KEYS_FILE is required in production")
+	default:
+		Keys = keys.Random()
+	}
+	if err != nil {
+		panic(err)
+	}
 }
 
 // HashPassword takes a password and converts it into a one time hash
@@ -29,13 +52,75 @@ func CheckPasswordHash(password string, hash string) bool {
 	return err == nil
 }
 
+// NewClaims returns the registered claims every access token carries
+func NewClaims() jwt.MapClaims {
+	now := time.Now()
+	return jwt.MapClaims{
+		"iss": config.JWTIssuer,
+		"aud": config.JWTAudience,
+		"iat": now.Unix(),
+		"nbf": now.Unix(),
+		"exp": now.Add(config.AccessTokenTTL).Unix(),
+		"jti": generate.GenerateRandomHexString(16),
+	}
+}
+
+// SignToken signs claims with the current key and tags the token with its kid
+func SignToken(claims jwt.Claims) (string, error) {
+	key := Keys.Current()
+	method := jwt.GetSigningMethod(key.Alg)
+	if method == nil {
+		return "", fmt.Errorf("Unsupported signing method: %v", key.Alg)
+	}
+	token := jwt.NewWithClaims(method, claims)
+	token.Header["kid"] = key.ID
+	if key.Secret != nil {
+		return token.SignedString(key.Secret)
+	}
+	return token.SignedString(key.Private)
+}
+
 // GetToken takes a jwt and returns a token struct
 func GetToken(Jwt string) (*jwt.Token, error) {
 	token, err := jwt.Parse(Jwt, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+		key := Keys.Current()
+		if kid, ok := token.Header["kid"].(string); ok {
+			var err error
+			if key, err = Keys.Lookup(kid); err != nil {
+				return nil, err
+			}
+		}
+		// the key decides the algorithm, never the token
+		if token.Method.Alg() != key.Alg {
 			return nil, fmt.Errorf("Unexpected signing method: %v", token.Header["alg"])
 		}
-		return []byte(config.JWTSecret), nil
+		if key.Secret != nil {
+			return key.Secret, nil
+		}
+		return key.Public, nil
 	})
-	return token, err
+	if err != nil {
+		return token, err
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		token.Valid = false
+		return token, errors.New("token claims are invalid")
+	}
+	if !claims.VerifyExpiresAt(time.Now().Unix(), true) {
+		token.Valid = false
+		return token, errors.New("token is expired")
+	}
+	if !claims.VerifyIssuer(config.JWTIssuer, true) || !claims.VerifyAudience(config.JWTAudience, true) {
+		token.Valid = false
+		return token, errors.New("token has an invalid issuer or audience")
+	}
+	return token, nil
+}
+
+// JWKSHandler publishes the public signing keys so other services can verify tokens
+func JWKSHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	json.NewEncoder(w).Encode(Keys.JWKS())
 }
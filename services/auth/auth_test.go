@@ -0,0 +1,186 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"go-lambda-graphql/config"
+	"go-lambda-graphql/services/generate"
+	"go-lambda-graphql/services/keys"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	jwt "github.com/dgrijalva/jwt-go"
+)
+
+func pemKey(t *testing.T, k interface{}) string {
+	der, err := x509.MarshalPKCS8PrivateKey(k)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+}
+
+func TestSigning(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+	raw, _ := json.Marshal(map[string]interface{}{
+		"current": "hs",
+		"keys": []map[string]string{
+			{"kid": "hs", "secret": base64.StdEncoding.EncodeToString(generate.GenerateRandomBytes(32))},
+			{"kid": "rs", "alg": "RS256", "private": pemKey(t, rsaKey)},
+			{"kid": "es", "alg": "ES256", "private": pemKey(t, ecKey)},
+			{"kid": "ed", "alg": "EdDSA", "private": pemKey(t, edKey)},
+		},
+	})
+	set, err := keys.Parse(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	Keys = set
+
+	for _, kid := range []string{"hs", "rs", "es", "ed"} {
+		t.Run("sign and verify "+kid, func(t *testing.T) {
+			Keys.SetCurrent(kid)
+			claims := NewClaims()
+			claims["id"] = 1
+			signed, err := SignToken(claims)
+			if err != nil {
+				t.Fatal(err)
+			}
+			token, err := GetToken(signed)
+			if err != nil || !token.Valid {
+				t.Fatalf("expected valid token, got %v", err)
+			}
+			if token.Header["kid"] != kid {
+				t.Errorf("expected kid %s, got %v", kid, token.Header["kid"])
+			}
+		})
+	}
+
+	t.Run("reject alg that does not match the key", func(t *testing.T) {
+		hs, _ := Keys.Lookup("hs")
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, NewClaims())
+		token.Header["kid"] = "rs"
+		signed, _ := token.SignedString(hs.Secret)
+		if _, err := GetToken(signed); err == nil {
+			t.Errorf("expected error")
+		}
+	})
+
+	t.Run("reject expired tokens", func(t *testing.T) {
+		Keys.SetCurrent("hs")
+		claims := NewClaims()
+		claims["exp"] = time.Now().Add(-time.Minute).Unix()
+		signed, _ := SignToken(claims)
+		if _, err := GetToken(signed); err == nil {
+			t.Errorf("expected error")
+		}
+	})
+
+	t.Run("reject tokens without expiry", func(t *testing.T) {
+		claims := NewClaims()
+		delete(claims, "exp")
+		signed, _ := SignToken(claims)
+		if _, err := GetToken(signed); err == nil {
+			t.Errorf("expected error")
+		}
+	})
+
+	t.Run("reject tokens for another audience", func(t *testing.T) {
+		claims := NewClaims()
+		claims["aud"] = "billing"
+		signed, _ := SignToken(claims)
+		if _, err := GetToken(signed); err == nil {
+			t.Errorf("expected error")
+		}
+	})
+
+	t.Run("publish only public keys", func(t *testing.T) {
+		jwks := Keys.JWKS()
+		if len(jwks.Keys) != 3 {
+			t.Fatalf("expected 3 public keys, got %d", len(jwks.Keys))
+		}
+		for _, k := range jwks.Keys {
+			if k.Kid == "hs" {
+				t.Errorf("hmac secret must not be published")
+			}
+		}
+	})
+}
+
+func TestTokenFromRequest(t *testing.T) {
+	t.Run("read bearer token", func(t *testing.T) {
+		r := httptest.NewRequest("POST", "/query", nil)
+		r.Header.Set("Authorization", "Bearer abc.def.ghi")
+		if TokenFromRequest(r) != "abc.def.ghi" {
+			t.Errorf("expected bearer token")
+		}
+	})
+
+	t.Run("fall back to cookie", func(t *testing.T) {
+		r := httptest.NewRequest("POST", "http://example.com/query", nil)
+		r.Header.Set("Origin", "http://example.com")
+		r.AddCookie(&http.Cookie{Name: config.AuthCookie, Value: "abc.def.ghi"})
+		if TokenFromRequest(r) != "abc.def.ghi" {
+			t.Errorf("expected cookie token")
+		}
+	})
+
+	t.Run("ignore cookie of cross site requests", func(t *testing.T) {
+		for _, header := range []map[string]string{
+			{"Sec-Fetch-Site": "cross-site"},
+			{"Sec-Fetch-Site": "same-site", "Origin": "http://example.com"},
+			{"Origin": "http://evil.example"},
+			{"Origin": "null"},
+			{},
+		} {
+			r := httptest.NewRequest("POST", "http://example.com/query", nil)
+			for k, v := range header {
+				r.Header.Set(k, v)
+			}
+			r.AddCookie(&http.Cookie{Name: config.AuthCookie, Value: "abc.def.ghi"})
+			if TokenFromRequest(r) != "" {
+				t.Errorf("expected no token with %v", header)
+			}
+		}
+	})
+
+	t.Run("read cookie of same origin requests", func(t *testing.T) {
+		for _, header := range []map[string]string{
+			{"Sec-Fetch-Site": "same-origin", "Origin": "http://example.com"},
+			{"Sec-Fetch-Site": "none"},
+		} {
+			r := httptest.NewRequest("POST", "http://example.com/query", nil)
+			for k, v := range header {
+				r.Header.Set(k, v)
+			}
+			r.AddCookie(&http.Cookie{Name: config.AuthCookie, Value: "abc.def.ghi"})
+			if TokenFromRequest(r) != "abc.def.ghi" {
+				t.Errorf("expected cookie token with %v", header)
+			}
+		}
+		r := httptest.NewRequest("GET", "http://example.com/query?query={viewer{id}}", nil)
+		r.AddCookie(&http.Cookie{Name: config.AuthCookie, Value: "abc.def.ghi"})
+		if TokenFromRequest(r) != "abc.def.ghi" {
+			t.Errorf("expected cookie token on get without origin")
+		}
+	})
+
+	t.Run("ignore other schemes", func(t *testing.T) {
+		r := httptest.NewRequest("POST", "/query", nil)
+		r.Header.Set("Authorization", "Basic dXNlcjpwYXNz")
+		if TokenFromRequest(r) != "" {
+			t.Errorf("expected no token")
+		}
+	})
+}
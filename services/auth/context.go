@@ -0,0 +1,73 @@
+package auth
+
+import (
+	"context"
+	"go-lambda-graphql/config"
+	"net/http"
+	"net/url"
+	"strings"
+
+	jwt "github.com/dgrijalva/jwt-go"
+)
+
+type contextKey int
+
+const principalKey contextKey = iota
+
+// Principal is the caller a request was authenticated as.
+// Err is set when a token was sent but did not validate, so resolvers can report why.
+type Principal struct {
+	Claims jwt.MapClaims
+	Err    error
+}
+
+// ID returns the usr id the token was issued to
+func (p *Principal) ID() int64 {
+	id, _ := p.Claims["id"].(float64)
+	return int64(id)
+}
+
+// WithPrincipal stores the authenticated caller in the context
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey, p)
+}
+
+// PrincipalFromContext returns the authenticated caller, or nil when no token was sent
+func PrincipalFromContext(ctx context.Context) *Principal {
+	p, _ := ctx.Value(principalKey).(*Principal)
+	return p
+}
+
+// TokenFromRequest returns the bearer token from the Authorization header, falling back to the auth cookie.
+// Browsers attach the cookie to requests other sites make too, so it is only read from same origin requests.
+func TokenFromRequest(r *http.Request) string {
+	header := r.Header.Get("Authorization")
+	if len(header) > 7 && strings.EqualFold(header[:7], "bearer ") {
+		return strings.TrimSpace(header[7:])
+	}
+	if !sameOrigin(r) {
+		return ""
+	}
+	if cookie, err := r.Cookie(config.AuthCookie); err == nil {
+		return cookie.Value
+	}
+	return ""
+}
+
+// sameOrigin reports whether r was sent by a page of this site, or typed in by the user,
+// from the Sec-Fetch-Site header or else the Origin header
+func sameOrigin(r *http.Request) bool {
+	switch r.Header.Get("Sec-Fetch-Site") {
+	case "same-origin", "none":
+		return true
+	case "":
+	default:
+		return false
+	}
+	if origin := r.Header.Get("Origin"); origin != "" {
+		u, err := url.Parse(origin)
+		return err == nil && u.Host == r.Host
+	}
+	// older browsers send neither on a cross site form post, only the requests that cannot mutate are trusted
+	return r.Method == http.MethodGet || r.Method == http.MethodHead
+}
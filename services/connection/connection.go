@@ -0,0 +1,171 @@
+package connection
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"go-lambda-graphql/services/apperr"
+
+	"github.com/volatiletech/sqlboiler/queries/qm"
+)
+
+// DefaultSize is the page size when neither first nor last is given
+const DefaultSize = 20
+
+// MaxSize is the largest page a client can ask for
+const MaxSize = 100
+
+// Args are the relay cursor connection arguments
+type Args struct {
+	First  *int32
+	After  *string
+	Last   *int32
+	Before *string
+}
+
+// Order is the keyset a connection is sorted by, ties on Column are broken by the id column
+type Order struct {
+	Column string
+	ID     string
+	Desc   bool
+}
+
+// Cursor points at a single edge by its sort value and id
+type Cursor struct {
+	Value interface{} `json:"v"`
+	ID    int64       `json:"id"`
+}
+
+// Encode returns the opaque string form of the cursor
+func (c Cursor) Encode() string {
+	raw, _ := json.Marshal(c)
+	return base64.URLEncoding.EncodeToString(raw)
+}
+
+// DecodeCursor parses a cursor returned by Encode
+func DecodeCursor(s string) (Cursor, error) {
+	var c Cursor
+	raw, err := base64.URLEncoding.DecodeString(s)
+	if err != nil {
+		return c, apperr.Errorf(apperr.ValidationFailed, "invalid cursor %s", s)
+	}
+	d := json.NewDecoder(bytes.NewReader(raw))
+	d.UseNumber()
+	if err := d.Decode(&c); err != nil {
+		return c, apperr.Errorf(apperr.ValidationFailed, "invalid cursor %s", s)
+	}
+	return c, nil
+}
+
+// Page is a single page of a connection, built from the connection arguments
+type Page struct {
+	order    Order
+	size     int
+	backward bool
+	after    *Cursor
+	before   *Cursor
+	hasMore  bool
+}
+
+// NewPage validates the connection arguments against the order
+func NewPage(args Args, order Order) (*Page, error) {
+	if args.First != nil && args.Last != nil {
+		return nil, apperr.New(apperr.ValidationFailed, "first and last cannot be combined")
+	}
+	p := &Page{order: order, size: DefaultSize}
+	if p.order.ID == "" {
+		p.order.ID = "id"
+	}
+	if args.First != nil {
+		p.size = int(*args.First)
+	}
+	if args.Last != nil {
+		p.size = int(*args.Last)
+		p.backward = true
+	}
+	if p.size < 0 || p.size > MaxSize {
+		return nil, apperr.Errorf(apperr.ValidationFailed, "page size must be between 0 and %d", MaxSize)
+	}
+	if args.After != nil {
+		c, err := DecodeCursor(*args.After)
+		if err != nil {
+			return nil, err
+		}
+		p.after = &c
+	}
+	if args.Before != nil {
+		c, err := DecodeCursor(*args.Before)
+		if err != nil {
+			return nil, err
+		}
+		p.before = &c
+	}
+	return p, nil
+}
+
+// Mods returns the query mods selecting the page, one extra row is fetched to know if there are more
+func (p *Page) Mods() []qm.QueryMod {
+	var mods []qm.QueryMod
+	keyset := "(" + p.order.Column + ", " + p.order.ID + ")"
+	next, prev := ">", "<"
+	if p.order.Desc {
+		next, prev = prev, next
+	}
+	if p.after != nil {
+		mods = append(mods, qm.Where(keyset+" "+next+" (?, ?)", p.after.Value, p.after.ID))
+	}
+	if p.before != nil {
+		mods = append(mods, qm.Where(keyset+" "+prev+" (?, ?)", p.before.Value, p.before.ID))
+	}
+	// walking backwards reads the rows in reverse and Indexes flips them back
+	desc := p.order.Desc != p.backward
+	direction := " asc"
+	if desc {
+		direction = " desc"
+	}
+	mods = append(mods,
+		qm.OrderBy(p.order.Column+direction+", "+p.order.ID+direction),
+		qm.Limit(p.size+1),
+	)
+	return mods
+}
+
+// Indexes takes the number of rows fetched with Mods and returns
+// the indexes of the rows that belong to the page, in connection order
+func (p *Page) Indexes(fetched int) []int {
+	n := fetched
+	if n > p.size {
+		n = p.size
+		p.hasMore = true
+	}
+	indexes := make([]int, n)
+	for i := range indexes {
+		if p.backward {
+			indexes[i] = n - 1 - i
+		} else {
+			indexes[i] = i
+		}
+	}
+	return indexes
+}
+
+// PageInfo describes the page once Indexes was called, cursors are the edge cursors in connection order
+func (p *Page) PageInfo(cursors []string) PageInfo {
+	info := PageInfo{
+		HasNextPage:     p.hasMore && !p.backward || p.backward && p.before != nil,
+		HasPreviousPage: p.hasMore && p.backward || !p.backward && p.after != nil,
+	}
+	if len(cursors) > 0 {
+		info.StartCursor = &cursors[0]
+		info.EndCursor = &cursors[len(cursors)-1]
+	}
+	return info
+}
+
+// PageInfo is the relay page info of a connection
+type PageInfo struct {
+	HasNextPage     bool
+	HasPreviousPage bool
+	StartCursor     *string
+	EndCursor       *string
+}
@@ -0,0 +1,73 @@
+package connection
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func intPtr(i int32) *int32 {
+	return &i
+}
+
+func TestConnection(t *testing.T) {
+	order := Order{Column: "name"}
+
+	t.Run("round trip cursors", func(t *testing.T) {
+		c, err := DecodeCursor(Cursor{Value: int64(1) << 60, ID: 7}.Encode())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if c.Value.(json.Number).String() != "1152921504606846976" || c.ID != 7 {
+			t.Errorf("unexpected cursor %v", c)
+		}
+		if _, err := DecodeCursor("not a cursor"); err == nil {
+			t.Errorf("expected error")
+		}
+	})
+
+	t.Run("reject invalid arguments", func(t *testing.T) {
+		if _, err := NewPage(Args{First: intPtr(1), Last: intPtr(1)}, order); err == nil {
+			t.Errorf("expected error combining first and last")
+		}
+		if _, err := NewPage(Args{First: intPtr(MaxSize + 1)}, order); err == nil {
+			t.Errorf("expected error for large page")
+		}
+		if _, err := NewPage(Args{First: intPtr(-1)}, order); err == nil {
+			t.Errorf("expected error for negative page")
+		}
+	})
+
+	t.Run("page forward", func(t *testing.T) {
+		p, _ := NewPage(Args{First: intPtr(2)}, order)
+		if indexes := p.Indexes(3); !reflect.DeepEqual(indexes, []int{0, 1}) {
+			t.Errorf("unexpected indexes %v", indexes)
+		}
+		info := p.PageInfo([]string{"a", "b"})
+		if !info.HasNextPage || info.HasPreviousPage || *info.StartCursor != "a" || *info.EndCursor != "b" {
+			t.Errorf("unexpected page info %+v", info)
+		}
+	})
+
+	t.Run("page backward", func(t *testing.T) {
+		before := Cursor{Value: "z", ID: 1}.Encode()
+		p, _ := NewPage(Args{Last: intPtr(2), Before: &before}, order)
+		if indexes := p.Indexes(3); !reflect.DeepEqual(indexes, []int{1, 0}) {
+			t.Errorf("unexpected indexes %v", indexes)
+		}
+		info := p.PageInfo(nil)
+		if !info.HasNextPage || !info.HasPreviousPage || info.StartCursor != nil {
+			t.Errorf("unexpected page info %+v", info)
+		}
+	})
+
+	t.Run("last page", func(t *testing.T) {
+		p, _ := NewPage(Args{}, order)
+		if indexes := p.Indexes(3); len(indexes) != 3 {
+			t.Errorf("unexpected indexes %v", indexes)
+		}
+		if info := p.PageInfo(nil); info.HasNextPage {
+			t.Errorf("expected no next page")
+		}
+	})
+}
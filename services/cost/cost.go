@@ -0,0 +1,247 @@
+package cost
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// the codes of the errors
+const (
+	TooDeep   = "QUERY_TOO_DEEP"
+	TooCostly = "QUERY_TOO_COSTLY"
+	// Unreadable refuses the documents the limits cannot be counted on, rather than running them uncounted
+	Unreadable = "QUERY_UNREADABLE"
+)
+
+// Field is a field of the schema
+type Field struct {
+	// Type is the named type the field returns, without its list and non null wrappers
+	Type string
+	// Weight is what resolving the field costs, at least 1
+	Weight int
+	// Paginated fields take first or last, their selections cost once per item of the page
+	Paginated bool
+}
+
+// Schema maps the names of the types to their fields
+type Schema map[string]map[string]Field
+
+// Limits are the largest depth and cost an operation can have
+type Limits struct {
+	MaxDepth int
+	MaxCost  int
+	// DefaultPageSize is the page size of the paginated fields queried without first or last
+	DefaultPageSize int
+}
+
+// Error reports an operation over a limit, Path leads to the field that went over it
+type Error struct {
+	Code  string
+	Limit int
+	// Value is the depth or cost counted when the limit was crossed, the whole operation may be larger
+	Value int
+	Path  []string
+}
+
+func (e *Error) Error() string {
+	if e.Code == Unreadable {
+		return "the operation of the query cannot be read"
+	}
+	what := "depth"
+	if e.Code == TooCostly {
+		what = "cost"
+	}
+	return fmt.Sprintf("query %s %d exceeds the limit of %d at %s", what, e.Value, e.Limit, strings.Join(e.Path, "."))
+}
+
+// Check returns an *Error when the operation of document named operationName goes over the limits,
+// or an Unreadable one when the document does not parse or has no such operation,
+// graphql-go may still run a document this parser misreads so it cannot be let through
+func Check(schema Schema, limits Limits, document string, operationName string, variables map[string]interface{}) *Error {
+	doc, err := parse(document)
+	if err != nil {
+		return &Error{Code: Unreadable}
+	}
+	op := doc.operation(operationName)
+	if op == nil {
+		return &Error{Code: Unreadable}
+	}
+	w := &walker{
+		schema:    schema,
+		limits:    limits,
+		fragments: doc.fragments,
+		variables: variables,
+		defaults:  op.defaults,
+		spreads:   map[string]bool{},
+	}
+	return w.selections(strings.Title(op.typ), op.selections, nil, 1)
+}
+
+// ErrNoOperation is returned for the documents without the operation named operationName
+var ErrNoOperation = errors.New("the document has no such operation")
+
+// Fields returns the fields the operation of document named operationName selects, as Type.field in the order
+// they first appear, fragments included. Unlike Check it fails on the documents it cannot parse
+// or find the operation of, so a caller relying on the fields can refuse them
+func Fields(schema Schema, document string, operationName string) ([]string, error) {
+	doc, err := parse(document)
+	if err != nil {
+		return nil, err
+	}
+	op := doc.operation(operationName)
+	if op == nil {
+		return nil, ErrNoOperation
+	}
+	w := &walker{
+		schema:    schema,
+		fragments: doc.fragments,
+		defaults:  op.defaults,
+		spreads:   map[string]bool{},
+		fields:    map[string]bool{},
+	}
+	w.selections(strings.Title(op.typ), op.selections, nil, 1)
+	return w.order, nil
+}
+
+// OperationType returns query, mutation or subscription, the type of the operation of document named operationName,
+// or of its only operation when operationName is empty, it is empty when the document does not parse or has no such operation,
+// such documents have to be refused
+func OperationType(document string, operationName string) string {
+	doc, err := parse(document)
+	if err != nil {
+		return ""
+	}
+	op := doc.operation(operationName)
+	if op == nil {
+		return ""
+	}
+	return op.typ
+}
+
+// operation returns the operation named name, or the only one when name is empty
+func (d *document) operation(name string) *operation {
+	var op *operation
+	for _, o := range d.operations {
+		if o.name == name || name == "" && len(d.operations) == 1 {
+			op = o
+		}
+	}
+	return op
+}
+
+// walker adds up the cost of every field times the page sizes of the paginated fields above it
+type walker struct {
+	schema    Schema
+	limits    Limits
+	fragments map[string]*fragment
+	variables map[string]interface{}
+	defaults  map[string]interface{}
+	// spreads are the fragments being walked, a cycle is left to the validation
+	spreads map[string]bool
+	cost    int
+	// fields collects the fields walked when not nil, order keeps them in the order they were met
+	fields map[string]bool
+	order  []string
+}
+
+func (w *walker) selections(typeName string, selections []selection, path []string, multiplier int) *Error {
+	for _, s := range selections {
+		var err *Error
+		switch {
+		case s.field != nil:
+			err = w.field(typeName, s.field, path, multiplier)
+		case s.inline != nil:
+			t := typeName
+			if s.inline.typeCondition != "" {
+				t = s.inline.typeCondition
+			}
+			err = w.selections(t, s.inline.selections, path, multiplier)
+		default:
+			f, ok := w.fragments[s.spread]
+			if !ok || w.spreads[s.spread] {
+				continue
+			}
+			w.spreads[s.spread] = true
+			err = w.selections(f.typeCondition, f.selections, path, multiplier)
+			delete(w.spreads, s.spread)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *walker) field(typeName string, f *field, parent []string, multiplier int) *Error {
+	// introspection only reads the schema
+	if strings.HasPrefix(f.name, "__") {
+		return nil
+	}
+	if w.fields != nil && !w.fields[typeName+"."+f.name] {
+		w.fields[typeName+"."+f.name] = true
+		w.order = append(w.order, typeName+"."+f.name)
+	}
+	key := f.name
+	if f.alias != "" {
+		key = f.alias
+	}
+	path := append(append([]string{}, parent...), key)
+	if w.limits.MaxDepth > 0 && len(path) > w.limits.MaxDepth {
+		return &Error{Code: TooDeep, Limit: w.limits.MaxDepth, Value: len(path), Path: path}
+	}
+	def := w.schema[typeName][f.name]
+	weight := def.Weight
+	if weight < 1 {
+		weight = 1
+	}
+	w.cost += weight * multiplier
+	if w.limits.MaxCost > 0 && w.cost > w.limits.MaxCost {
+		return &Error{Code: TooCostly, Limit: w.limits.MaxCost, Value: w.cost, Path: path}
+	}
+	if def.Paginated {
+		multiplier *= w.pageSize(f)
+	}
+	return w.selections(def.Type, f.selections, path, multiplier)
+}
+
+// pageSize returns the largest of the first and last arguments of a paginated field
+func (w *walker) pageSize(f *field) int {
+	size, given := 0, false
+	for _, arg := range []string{"first", "last"} {
+		if n, ok := w.intArgument(f.arguments[arg]); ok {
+			given = true
+			if n > size {
+				size = n
+			}
+		}
+	}
+	if !given {
+		size = w.limits.DefaultPageSize
+	}
+	if size < 1 {
+		// an empty page still resolves its fields once, so fragments cannot be walked for free
+		size = 1
+	}
+	return size
+}
+
+func (w *walker) intArgument(value interface{}) (int, bool) {
+	if v, ok := value.(variable); ok {
+		value, ok = w.variables[string(v)]
+		if !ok {
+			value = w.defaults[string(v)]
+		}
+	}
+	switch n := value.(type) {
+	case int64:
+		return int(n), true
+	case float64:
+		return int(n), true
+	case int:
+		return n, true
+	case int32:
+		return int(n), true
+	}
+	return 0, false
+}
@@ -0,0 +1,191 @@
+package cost
+
+import (
+	"reflect"
+	"testing"
+)
+
+var schema = Schema{
+	"Query": {
+		"viewer":     {Type: "User"},
+		"submittals": {Type: "SubmittalLogFactConnection", Paginated: true},
+		"search":     {Type: "SubmittalSearchConnection", Weight: 10, Paginated: true},
+	},
+	"User": {
+		"id":                {Type: "ID"},
+		"name":              {Type: "String"},
+		"friendsConnection": {Type: "UserConnection", Paginated: true},
+	},
+	"UserConnection":             {"edges": {Type: "UserEdge"}, "totalCount": {Type: "Int"}},
+	"UserEdge":                   {"node": {Type: "User"}},
+	"SubmittalLogFactConnection": {"edges": {Type: "SubmittalLogFactEdge"}},
+	"SubmittalLogFactEdge":       {"node": {Type: "SubmittalLogFact"}},
+	"SubmittalLogFact":           {"id": {Type: "ID"}, "title": {Type: "String"}},
+}
+
+func TestCheck(t *testing.T) {
+	limits := Limits{MaxDepth: 5, MaxCost: 300, DefaultPageSize: 20}
+
+	t.Run("accept queries within the limits", func(t *testing.T) {
+		for _, q := range []string{
+			`{ viewer { id name } }`,
+			`query Q($n: Int = 10) { submittals(first: $n) { edges { node { id title } } } }`,
+			`query { __schema { types { fields { type { ofType { ofType { ofType { name } } } } } } } }`,
+			`fragment F on User { ...F } { viewer { ...F } }`,
+			"query Q {\r\n  viewer {\r\n    id\r\n  }\r\n}\r\n",
+		} {
+			if err := Check(schema, limits, q, "", nil); err != nil {
+				t.Errorf("unexpected error for %s: %v", q, err)
+			}
+		}
+	})
+
+	t.Run("refuse documents that cannot be read", func(t *testing.T) {
+		for _, c := range []struct{ document, operationName string }{
+			{`not graphql {`, ""},
+			{`query A { viewer { id } } query B { viewer { id } }`, ""},
+			{`query A { viewer { id } }`, "B"},
+		} {
+			if err := Check(schema, limits, c.document, c.operationName, nil); err == nil || err.Code != Unreadable {
+				t.Errorf("expected %q to be unreadable, got %v", c.document, err)
+			}
+		}
+	})
+
+	t.Run("count the operation after a comment ending in a carriage return", func(t *testing.T) {
+		for _, q := range []string{
+			"# x\r{ viewer { friendsConnection { edges { node { friendsConnection { edges { node { id } } } } } } } }",
+			"# x\r\n{ viewer { friendsConnection { edges { node { friendsConnection { edges { node { id } } } } } } } }",
+		} {
+			if err := Check(schema, limits, q, "", nil); err == nil || err.Code != TooDeep {
+				t.Errorf("expected %q to be too deep, got %v", q, err)
+			}
+		}
+	})
+
+	t.Run("reject deep queries", func(t *testing.T) {
+		q := `{ viewer { friendsConnection { edges { node { me: friendsConnection { totalCount } } } } } }`
+		err := Check(schema, limits, q, "", nil)
+		if err == nil || err.Code != TooDeep || !reflect.DeepEqual(err.Path, []string{"viewer", "friendsConnection", "edges", "node", "me", "totalCount"}) {
+			t.Errorf("unexpected error %v", err)
+		}
+	})
+
+	t.Run("multiply by the page size", func(t *testing.T) {
+		q := `query Q($n: Int) { submittals(first: $n) { edges { node { ...F } } } } fragment F on SubmittalLogFact { id title }`
+		if err := Check(schema, limits, q, "Q", map[string]interface{}{"n": 100.0}); err == nil || err.Code != TooCostly {
+			t.Errorf("expected the page of 100 to be too costly, got %v", err)
+		}
+		if err := Check(schema, limits, q, "Q", map[string]interface{}{"n": 10.0}); err != nil {
+			t.Errorf("unexpected error %v", err)
+		}
+		// without first, the default page of 20 costs 1 + 20 * 4
+		err := Check(schema, Limits{MaxCost: 80, DefaultPageSize: 20}, q, "Q", nil)
+		if err == nil || err.Value != 81 || !reflect.DeepEqual(err.Path, []string{"submittals", "edges", "node", "title"}) {
+			t.Errorf("unexpected error %v", err)
+		}
+	})
+
+	t.Run("weigh fields", func(t *testing.T) {
+		q := `{ a: search(first: 1) { __typename } b: search(first: 1) { __typename } }`
+		err := Check(schema, Limits{MaxCost: 15}, q, "", nil)
+		if err == nil || err.Value != 20 || !reflect.DeepEqual(err.Path, []string{"b"}) {
+			t.Errorf("unexpected error %v", err)
+		}
+	})
+
+	t.Run("list the selected fields", func(t *testing.T) {
+		q := `query Q { viewer { ...F ... on User { id } } } fragment F on User { friendsConnection { edges { node { name } } } }`
+		fields, err := Fields(schema, q, "Q")
+		expected := []string{"Query.viewer", "User.friendsConnection", "UserConnection.edges", "UserEdge.node", "User.name", "User.id"}
+		if err != nil || !reflect.DeepEqual(fields, expected) {
+			t.Errorf("unexpected fields %v, %v", fields, err)
+		}
+		if _, err := Fields(schema, `not graphql {`, ""); err == nil {
+			t.Errorf("expected a syntax error")
+		}
+		if _, err := Fields(schema, q, "Other"); err != ErrNoOperation {
+			t.Errorf("expected no operation, got %v", err)
+		}
+		// graphql-go ends comments at a carriage return too, the mutation after it must not go unseen
+		fields, err = Fields(schema, "# x\rmutation { grantRole(role: \"admin\") }", "")
+		if err != nil || !reflect.DeepEqual(fields, []string{"Mutation.grantRole"}) {
+			t.Errorf("unexpected fields %v, %v", fields, err)
+		}
+	})
+
+	t.Run("pick the named operation", func(t *testing.T) {
+		q := `query Small { viewer { id } } query Large { submittals(first: 100) { edges { node { id title } } } }`
+		if err := Check(schema, limits, q, "Small", nil); err != nil {
+			t.Errorf("unexpected error %v", err)
+		}
+		if err := Check(schema, limits, q, "Large", nil); err == nil {
+			t.Errorf("expected an error")
+		}
+	})
+}
+
+func TestOperationType(t *testing.T) {
+	for _, c := range []struct {
+		document, operationName, want string
+	}{
+		{`{ viewer { id } }`, "", "query"},
+		{`query { viewer { id } }`, "", "query"},
+		{`mutation Logout { logout }`, "", "mutation"},
+		{`subscription($id: ID) @live { userUpdated(id: $id) { id } }`, "", "subscription"},
+		{"# mutation\nquery Q($f: SubmittalLogFilter = {title: {contains: \"}mutation{\"}}) { submittals(filter: $f) { totalCount } }", "", "query"},
+		{"query A { a } mutation B { b }", "B", "mutation"},
+		{"query A { a } mutation B { b }", "A", "query"},
+		{"query A { a } mutation B { b }", "", ""},
+		{"query A { a }", "B", ""},
+		{"mutation { broken(", "", ""},
+		{"# x\rmutation { logout }", "", "mutation"},
+		{"# query\r\nmutation M {\r\n  logout\r\n}\r\n", "", "mutation"},
+		{"fragment F on User { id } mutation M { updateUser(name: \"\"\"a \\\"\"\" {\"\"\") { ...F } }", "", "mutation"},
+	} {
+		if got := OperationType(c.document, c.operationName); got != c.want {
+			t.Errorf("OperationType(%q, %q) = %q, want %q", c.document, c.operationName, got, c.want)
+		}
+	}
+}
+
+func TestParseSchema(t *testing.T) {
+	source := `
+		"""the entry points"""
+		schema { query: Query mutation: Mutation }
+		scalar Time
+		directive @auth(requires: Permission!) on FIELD_DEFINITION | OBJECT
+		enum Permission { MANAGE_ROLES @deprecated READ }
+		union Result = | User | Role
+		input Page { first: Int = 10, after: String @deprecated(reason: "no") }
+		interface Node { id: ID! }
+		type User implements & Node & Named {
+			# a comment
+			"the name"
+			name: String!
+			friends(first: Int, after: String): [User!]! @cost(weight: 5) @auth(requires: READ)
+		}
+	`
+	types, err := ParseSchema(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := types["Page"]; ok {
+		t.Errorf("inputs are not output types")
+	}
+	if f := types["Node"]["id"]; f.Type != "ID" {
+		t.Errorf("unexpected id field %+v", f)
+	}
+	f := types["User"]["friends"]
+	expected := SchemaField{
+		Type:       "User",
+		Arguments:  []string{"first", "after"},
+		Directives: map[string]map[string]interface{}{"cost": {"weight": int64(5)}, "auth": {"requires": "READ"}},
+	}
+	if !reflect.DeepEqual(f, expected) {
+		t.Errorf("unexpected friends field %+v", f)
+	}
+	if _, err := ParseSchema("type User { name: }"); err == nil {
+		t.Errorf("expected a syntax error")
+	}
+}
@@ -0,0 +1,344 @@
+package cost
+
+import (
+	"errors"
+	"strconv"
+)
+
+// the parts of an executable document the limits, the permissions and the operation type look at,
+// everything else is skipped
+
+type document struct {
+	operations []*operation
+	fragments  map[string]*fragment
+}
+
+type operation struct {
+	typ        string
+	name       string
+	defaults   map[string]interface{}
+	selections []selection
+}
+
+type fragment struct {
+	typeCondition string
+	selections    []selection
+}
+
+// selection is a field, a fragment spread or an inline fragment
+type selection struct {
+	field      *field
+	spread     string
+	inline     *fragment
+	isFragment bool
+}
+
+type field struct {
+	alias      string
+	name       string
+	arguments  map[string]interface{}
+	selections []selection
+}
+
+// variable is an argument value referring to a variable of the operation
+type variable string
+
+var errSyntax = errors.New("syntax error")
+
+type parser struct {
+	lexer
+}
+
+// parse reads a document, any error makes the limits step aside for the validation of the schema to report it
+func parse(source string) (doc *document, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			if r != errSyntax {
+				panic(r)
+			}
+			doc, err = nil, errSyntax
+		}
+	}()
+	p := &parser{lexer{source: source}}
+	p.next()
+	doc = &document{fragments: map[string]*fragment{}}
+	for p.kind != eofToken {
+		switch {
+		case p.peek('{'):
+			doc.operations = append(doc.operations, &operation{typ: "query", selections: p.selectionSet()})
+		case p.peekName("fragment"):
+			p.next()
+			fragmentName := p.name()
+			p.expectName("on")
+			f := &fragment{typeCondition: p.name()}
+			p.directives()
+			f.selections = p.selectionSet()
+			doc.fragments[fragmentName] = f
+		case p.peekName("query"), p.peekName("mutation"), p.peekName("subscription"):
+			op := &operation{typ: p.name(), defaults: map[string]interface{}{}}
+			if p.kind == nameToken {
+				op.name = p.name()
+			}
+			if p.skip('(') {
+				for !p.skip(')') {
+					p.expect('$')
+					v := p.name()
+					p.expect(':')
+					p.typeRef()
+					if p.skip('=') {
+						op.defaults[v] = p.value()
+					}
+					p.directives()
+				}
+			}
+			p.directives()
+			op.selections = p.selectionSet()
+			doc.operations = append(doc.operations, op)
+		default:
+			panic(errSyntax)
+		}
+	}
+	return doc, nil
+}
+
+func (p *parser) selectionSet() []selection {
+	p.expect('{')
+	var selections []selection
+	for !p.skip('}') {
+		if p.skip('.') {
+			// the lexer reads ... as three dots
+			p.expect('.')
+			p.expect('.')
+			switch {
+			case p.peekName("on"):
+				p.next()
+				f := &fragment{typeCondition: p.name()}
+				p.directives()
+				f.selections = p.selectionSet()
+				selections = append(selections, selection{inline: f, isFragment: true})
+			case p.kind == nameToken:
+				selections = append(selections, selection{spread: p.name(), isFragment: true})
+				p.directives()
+			default:
+				f := &fragment{}
+				p.directives()
+				f.selections = p.selectionSet()
+				selections = append(selections, selection{inline: f, isFragment: true})
+			}
+			continue
+		}
+		f := &field{name: p.name()}
+		if p.skip(':') {
+			f.alias, f.name = f.name, p.name()
+		}
+		f.arguments = p.arguments()
+		p.directives()
+		if p.peek('{') {
+			f.selections = p.selectionSet()
+		}
+		selections = append(selections, selection{field: f})
+	}
+	return selections
+}
+
+func (p *parser) arguments() map[string]interface{} {
+	if !p.skip('(') {
+		return nil
+	}
+	args := map[string]interface{}{}
+	for !p.skip(')') {
+		n := p.name()
+		p.expect(':')
+		args[n] = p.value()
+	}
+	return args
+}
+
+// directives maps the names of the directives to their arguments
+func (p *parser) directives() map[string]map[string]interface{} {
+	var directives map[string]map[string]interface{}
+	for p.skip('@') {
+		if directives == nil {
+			directives = map[string]map[string]interface{}{}
+		}
+		n := p.name()
+		directives[n] = p.arguments()
+	}
+	return directives
+}
+
+// typeRef returns the named type of a type reference, without its list and non null wrappers
+func (p *parser) typeRef() string {
+	var n string
+	if p.skip('[') {
+		n = p.typeRef()
+		p.expect(']')
+	} else {
+		n = p.name()
+	}
+	p.skip('!')
+	return n
+}
+
+// value returns ints as int64, enum values and the other names as string and variables as variable,
+// the other values as nil
+func (p *parser) value() interface{} {
+	switch {
+	case p.skip('$'):
+		return variable(p.name())
+	case p.kind == numberToken:
+		n, err := strconv.ParseInt(p.text, 10, 64)
+		p.next()
+		if err != nil {
+			return nil
+		}
+		return n
+	case p.kind == nameToken:
+		return p.name()
+	case p.kind == stringToken:
+		p.next()
+	case p.skip('['):
+		for !p.skip(']') {
+			p.value()
+		}
+	case p.skip('{'):
+		for !p.skip('}') {
+			p.name()
+			p.expect(':')
+			p.value()
+		}
+	default:
+		panic(errSyntax)
+	}
+	return nil
+}
+
+func (p *parser) peek(c byte) bool {
+	return p.kind == punctuatorToken && p.text[0] == c
+}
+
+func (p *parser) peekName(text string) bool {
+	return p.kind == nameToken && p.text == text
+}
+
+func (p *parser) skip(c byte) bool {
+	if p.peek(c) {
+		p.next()
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(c byte) {
+	if !p.skip(c) {
+		panic(errSyntax)
+	}
+}
+
+func (p *parser) expectName(text string) {
+	if !p.peekName(text) {
+		panic(errSyntax)
+	}
+	p.next()
+}
+
+func (p *parser) name() string {
+	if p.kind != nameToken {
+		panic(errSyntax)
+	}
+	text := p.text
+	p.next()
+	return text
+}
+
+type tokenKind int
+
+const (
+	eofToken tokenKind = iota
+	punctuatorToken
+	nameToken
+	numberToken
+	stringToken
+)
+
+type lexer struct {
+	source string
+	pos    int
+	kind   tokenKind
+	text   string
+}
+
+// next reads the next token into kind and text
+func (l *lexer) next() {
+	for l.pos < len(l.source) {
+		c := l.source[l.pos]
+		if c == '#' {
+			// a comment ends at either line terminator, like in graphql-go, or the lexers would disagree on the operation
+			for l.pos < len(l.source) && l.source[l.pos] != '\n' && l.source[l.pos] != '\r' {
+				l.pos++
+			}
+			continue
+		}
+		if c != ' ' && c != '\t' && c != '\n' && c != '\r' && c != ',' {
+			break
+		}
+		l.pos++
+	}
+	if l.pos >= len(l.source) {
+		l.kind, l.text = eofToken, ""
+		return
+	}
+	start := l.pos
+	c := l.source[l.pos]
+	switch {
+	case c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z':
+		for l.pos < len(l.source) && isNameChar(l.source[l.pos]) {
+			l.pos++
+		}
+		l.kind = nameToken
+	case c == '-' || c >= '0' && c <= '9':
+		l.pos++
+		for l.pos < len(l.source) && (isNameChar(l.source[l.pos]) || l.source[l.pos] == '.' || l.source[l.pos] == '+' || l.source[l.pos] == '-') {
+			l.pos++
+		}
+		l.kind = numberToken
+	case c == '"':
+		l.pos = skipString(l.source, l.pos) + 1
+		l.kind = stringToken
+	case c == '!' || c == '$' || c == '&' || c == '(' || c == ')' || c == '.' || c == ':' || c == '=' || c == '@' || c == '[' || c == ']' || c == '{' || c == '|' || c == '}':
+		l.pos++
+		l.kind = punctuatorToken
+	default:
+		panic(errSyntax)
+	}
+	l.text = l.source[start:l.pos]
+}
+
+func isNameChar(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+// skipString returns the index of the closing quote of the string or block string starting at i
+func skipString(source string, i int) int {
+	if i+2 < len(source) && source[i+1] == '"' && source[i+2] == '"' {
+		for i += 3; i+2 < len(source); i++ {
+			if source[i] == '\\' && i+3 < len(source) && source[i+1:i+4] == `"""` {
+				i += 3
+			} else if source[i:i+3] == `"""` {
+				return i + 2
+			}
+		}
+		panic(errSyntax)
+	}
+	for i++; i < len(source); i++ {
+		switch source[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		case '\n':
+			panic(errSyntax)
+		}
+	}
+	panic(errSyntax)
+}
@@ -0,0 +1,147 @@
+package cost
+
+// SchemaField is a field of an object or interface type of a schema document
+type SchemaField struct {
+	// Type is the named type the field returns, without its list and non null wrappers
+	Type string
+	// Arguments are the names of the arguments the field takes
+	Arguments []string
+	// Directives maps the directives of the field to their arguments, see value for how they are read
+	Directives map[string]map[string]interface{}
+}
+
+// ParseSchema reads the fields of the object and interface types of a schema document,
+// the other definitions are only checked to be well formed
+func ParseSchema(source string) (types map[string]map[string]SchemaField, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			if r != errSyntax {
+				panic(r)
+			}
+			types, err = nil, errSyntax
+		}
+	}()
+	p := &parser{lexer{source: source}}
+	p.next()
+	types = map[string]map[string]SchemaField{}
+	for p.kind != eofToken {
+		p.description()
+		switch {
+		case p.peekName("type"), p.peekName("interface"):
+			p.next()
+			typeName := p.name()
+			if p.peekName("implements") {
+				p.next()
+				p.skip('&')
+				for p.kind == nameToken {
+					p.name()
+					p.skip('&')
+				}
+			}
+			p.directives()
+			types[typeName] = p.fieldDefinitions()
+		case p.peekName("input"):
+			p.next()
+			p.name()
+			p.directives()
+			p.expect('{')
+			for !p.skip('}') {
+				p.inputValueDefinition()
+			}
+		case p.peekName("enum"):
+			p.next()
+			p.name()
+			p.directives()
+			p.expect('{')
+			for !p.skip('}') {
+				p.description()
+				p.name()
+				p.directives()
+			}
+		case p.peekName("scalar"):
+			p.next()
+			p.name()
+			p.directives()
+		case p.peekName("union"):
+			p.next()
+			p.name()
+			p.directives()
+			p.expect('=')
+			p.skip('|')
+			p.name()
+			for p.skip('|') {
+				p.name()
+			}
+		case p.peekName("directive"):
+			p.next()
+			p.expect('@')
+			p.name()
+			p.argumentDefinitions()
+			if p.peekName("repeatable") {
+				p.next()
+			}
+			p.expectName("on")
+			p.skip('|')
+			p.name()
+			for p.skip('|') {
+				p.name()
+			}
+		case p.peekName("schema"):
+			p.next()
+			p.directives()
+			p.expect('{')
+			for !p.skip('}') {
+				p.name()
+				p.expect(':')
+				p.name()
+			}
+		default:
+			panic(errSyntax)
+		}
+	}
+	return types, nil
+}
+
+func (p *parser) fieldDefinitions() map[string]SchemaField {
+	fields := map[string]SchemaField{}
+	p.expect('{')
+	for !p.skip('}') {
+		p.description()
+		fieldName := p.name()
+		arguments := p.argumentDefinitions()
+		p.expect(':')
+		fields[fieldName] = SchemaField{Type: p.typeRef(), Arguments: arguments, Directives: p.directives()}
+	}
+	return fields
+}
+
+// argumentDefinitions returns the names of the arguments
+func (p *parser) argumentDefinitions() []string {
+	if !p.skip('(') {
+		return nil
+	}
+	var arguments []string
+	for !p.skip(')') {
+		arguments = append(arguments, p.inputValueDefinition())
+	}
+	return arguments
+}
+
+// inputValueDefinition reads an argument or an input field and returns its name
+func (p *parser) inputValueDefinition() string {
+	p.description()
+	n := p.name()
+	p.expect(':')
+	p.typeRef()
+	if p.skip('=') {
+		p.value()
+	}
+	p.directives()
+	return n
+}
+
+func (p *parser) description() {
+	if p.kind == stringToken {
+		p.next()
+	}
+}
@@ -0,0 +1,182 @@
+package filter
+
+import (
+	"go-lambda-graphql/services/apperr"
+	"reflect"
+	"strings"
+
+	"github.com/lib/pq"
+	"github.com/volatiletech/sqlboiler/queries/qm"
+)
+
+// Kind is the type of a filterable column, it decides which operators apply
+type Kind int
+
+const (
+	// Scalar columns support eq, in and range
+	Scalar Kind = iota
+	// Text columns support eq, in and contains
+	Text
+	// Array columns support contains and overlaps
+	Array
+)
+
+// Columns whitelists the columns a filter can reach, only these names ever end up in the sql
+type Columns map[string]Kind
+
+// Clause is a sql condition with its placeholder arguments, the zero Clause matches every row
+type Clause struct {
+	SQL  string
+	Args []interface{}
+}
+
+// Empty reports whether the clause has no condition
+func (c Clause) Empty() bool {
+	return c.SQL == ""
+}
+
+// Mods returns the where mod of the clause, or nothing for the empty clause
+func (c Clause) Mods() []qm.QueryMod {
+	if c.Empty() {
+		return nil
+	}
+	return []qm.QueryMod{qm.Where(c.SQL, c.Args...)}
+}
+
+// And joins the clauses so that all of them must match, empty clauses are skipped
+func And(clauses ...Clause) Clause {
+	return join(" and ", clauses)
+}
+
+// Or joins the clauses so that any of them must match,
+// an empty clause matches every row and so makes the whole Or empty
+func Or(clauses ...Clause) Clause {
+	for _, c := range clauses {
+		if c.Empty() {
+			return Clause{}
+		}
+	}
+	return join(" or ", clauses)
+}
+
+func join(op string, clauses []Clause) Clause {
+	var parts []string
+	var args []interface{}
+	for _, c := range clauses {
+		if c.Empty() {
+			continue
+		}
+		parts = append(parts, c.SQL)
+		args = append(args, c.Args...)
+	}
+	if len(parts) == 0 {
+		return Clause{}
+	}
+	return Clause{SQL: "(" + strings.Join(parts, op) + ")", Args: args}
+}
+
+// Builder compiles operators on whitelisted columns into clauses,
+// the first invalid operator is kept in Err and every later call is a no-op
+type Builder struct {
+	columns Columns
+	err     error
+}
+
+// New returns a builder restricted to columns
+func New(columns Columns) *Builder {
+	return &Builder{columns: columns}
+}
+
+// Err returns the first error met while building
+func (b *Builder) Err() error {
+	return b.err
+}
+
+// check verifies column is whitelisted with one of kinds
+func (b *Builder) check(column string, kinds ...Kind) bool {
+	if b.err != nil {
+		return false
+	}
+	kind, ok := b.columns[column]
+	if !ok {
+		b.err = apperr.Errorf(apperr.ValidationFailed, "cannot filter on %s", column)
+		return false
+	}
+	for _, k := range kinds {
+		if k == kind {
+			return true
+		}
+	}
+	b.err = apperr.Errorf(apperr.ValidationFailed, "operator not supported on %s", column)
+	return false
+}
+
+// Eq matches rows where column equals value
+func (b *Builder) Eq(column string, value interface{}) Clause {
+	if !b.check(column, Scalar, Text) {
+		return Clause{}
+	}
+	return Clause{SQL: column + " = ?", Args: []interface{}{value}}
+}
+
+// In matches rows where column is one of values, values must be a slice
+func (b *Builder) In(column string, values interface{}) Clause {
+	if !b.check(column, Scalar, Text) || !b.slice(values) {
+		return Clause{}
+	}
+	return Clause{SQL: column + " = ANY(?)", Args: []interface{}{pq.Array(values)}}
+}
+
+// Range matches rows where column is between from and to inclusive, a nil bound is open
+func (b *Builder) Range(column string, from, to interface{}) Clause {
+	if !b.check(column, Scalar) {
+		return Clause{}
+	}
+	var clauses []Clause
+	if from != nil {
+		clauses = append(clauses, Clause{SQL: column + " >= ?", Args: []interface{}{from}})
+	}
+	if to != nil {
+		clauses = append(clauses, Clause{SQL: column + " <= ?", Args: []interface{}{to}})
+	}
+	return And(clauses...)
+}
+
+// Contains matches rows where the text column contains s, ignoring case
+func (b *Builder) Contains(column string, s string) Clause {
+	if !b.check(column, Text) {
+		return Clause{}
+	}
+	return Clause{SQL: column + " ilike ?", Args: []interface{}{"%" + escapeLike(s) + "%"}}
+}
+
+// ArrayContains matches rows where the array column holds every one of values
+func (b *Builder) ArrayContains(column string, values interface{}) Clause {
+	if !b.check(column, Array) || !b.slice(values) {
+		return Clause{}
+	}
+	return Clause{SQL: column + " @> ?", Args: []interface{}{pq.Array(values)}}
+}
+
+// Overlaps matches rows where the array column holds any of values
+func (b *Builder) Overlaps(column string, values interface{}) Clause {
+	if !b.check(column, Array) || !b.slice(values) {
+		return Clause{}
+	}
+	return Clause{SQL: column + " && ?", Args: []interface{}{pq.Array(values)}}
+}
+
+func (b *Builder) slice(values interface{}) bool {
+	if v := reflect.ValueOf(values); v.Kind() != reflect.Slice {
+		b.err = apperr.New(apperr.ValidationFailed, "list operators need a list of values")
+		return false
+	}
+	return true
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// escapeLike makes s match literally inside a like pattern
+func escapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
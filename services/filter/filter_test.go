@@ -0,0 +1,59 @@
+package filter
+
+import (
+	"testing"
+)
+
+func TestFilter(t *testing.T) {
+	columns := Columns{"project_id": Scalar, "title": Text, "ball_in_court_ids": Array}
+
+	t.Run("compile nested clauses", func(t *testing.T) {
+		b := New(columns)
+		c := And(
+			b.Eq("project_id", int64(1)),
+			Or(b.Contains("title", "50%_off"), b.Overlaps("ball_in_court_ids", []int64{2, 3})),
+			b.Range("project_id", nil, int64(9)),
+			Or(),
+		)
+		if b.Err() != nil {
+			t.Fatal(b.Err())
+		}
+		sql := "(project_id = ? and (title ilike ? or ball_in_court_ids && ?) and (project_id <= ?))"
+		if c.SQL != sql {
+			t.Errorf("unexpected sql %s", c.SQL)
+		}
+		if len(c.Args) != 4 || c.Args[1] != `%50\%\_off%` {
+			t.Errorf("unexpected args %v", c.Args)
+		}
+	})
+
+	t.Run("reject columns outside the whitelist", func(t *testing.T) {
+		b := New(columns)
+		b.Eq("password; drop table usr", 1)
+		if b.Err() == nil {
+			t.Errorf("expected error")
+		}
+	})
+
+	t.Run("reject operators on the wrong kind", func(t *testing.T) {
+		b := New(columns)
+		b.Contains("project_id", "1")
+		if b.Err() == nil {
+			t.Errorf("expected error")
+		}
+		b = New(columns)
+		b.In("project_id", int64(1))
+		if b.Err() == nil {
+			t.Errorf("expected error for a single value")
+		}
+	})
+
+	t.Run("empty filter matches everything", func(t *testing.T) {
+		if c := Or(New(columns).Eq("title", "a"), And()); !c.Empty() {
+			t.Errorf("expected an empty clause, got %s", c.SQL)
+		}
+		if mods := And().Mods(); mods != nil {
+			t.Errorf("unexpected mods %v", mods)
+		}
+	})
+}
@@ -0,0 +1,144 @@
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"net/http"
+	"net/url"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+)
+
+// Handler is the lambda handler signature for API Gateway proxy events
+type Handler func(ctx context.Context, e events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error)
+
+// NewHandler wraps a http.Handler so it can serve API Gateway proxy events
+func NewHandler(h http.Handler) Handler {
+	return func(ctx context.Context, e events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		r, err := NewRequest(ctx, e)
+		if err != nil {
+			return events.APIGatewayProxyResponse{}, err
+		}
+		w := NewResponseWriter()
+		h.ServeHTTP(w, r)
+		return w.Response(), nil
+	}
+}
+
+// ListenAndServe starts the lambda runtime loop and serves h until the process dies
+func ListenAndServe(h http.Handler) {
+	lambda.Start(NewHandler(h))
+}
+
+// NewRequest converts an API Gateway proxy event into a http.Request
+func NewRequest(ctx context.Context, e events.APIGatewayProxyRequest) (*http.Request, error) {
+	u, err := url.Parse(e.Path)
+	if err != nil {
+		return nil, err
+	}
+	q := url.Values{}
+	for k, v := range e.QueryStringParameters {
+		q.Set(k, v)
+	}
+	for k, vs := range e.MultiValueQueryStringParameters {
+		q[k] = vs
+	}
+	u.RawQuery = q.Encode()
+
+	body := []byte(e.Body)
+	if e.IsBase64Encoded {
+		body, err = base64.StdEncoding.DecodeString(e.Body)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	r, err := http.NewRequest(e.HTTPMethod, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range e.Headers {
+		r.Header.Set(k, v)
+	}
+	for k, vs := range e.MultiValueHeaders {
+		r.Header.Del(k)
+		for _, v := range vs {
+			r.Header.Add(k, v)
+		}
+	}
+	r.ContentLength = int64(len(body))
+	r.RemoteAddr = e.RequestContext.Identity.SourceIP
+	r.RequestURI = u.RequestURI()
+	r.Host = r.Header.Get("Host")
+	if r.Header.Get("X-Request-Id") == "" && e.RequestContext.RequestID != "" {
+		r.Header.Set("X-Request-Id", e.RequestContext.RequestID)
+	}
+	return r.WithContext(ctx), nil
+}
+
+// ResponseWriter buffers a response so it can be returned as an API Gateway proxy response
+type ResponseWriter struct {
+	header http.Header
+	body   bytes.Buffer
+	status int
+}
+
+// NewResponseWriter returns an empty ResponseWriter
+func NewResponseWriter() *ResponseWriter {
+	return &ResponseWriter{header: http.Header{}}
+}
+
+// Header returns the response headers
+func (w *ResponseWriter) Header() http.Header {
+	return w.header
+}
+
+// Write buffers the response body
+func (w *ResponseWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		if w.header.Get("Content-Type") == "" {
+			w.header.Set("Content-Type", http.DetectContentType(b))
+		}
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.body.Write(b)
+}
+
+// WriteHeader records the status code, only the first call has any effect
+func (w *ResponseWriter) WriteHeader(status int) {
+	if w.status != 0 {
+		return
+	}
+	w.status = status
+}
+
+// Response converts the buffered response into an API Gateway proxy response.
+// Binary or compressed bodies (e.g. gzip from httpgzip) are base64 encoded,
+// which requires binary media types to be enabled on the API Gateway stage.
+func (w *ResponseWriter) Response() events.APIGatewayProxyResponse {
+	if w.status == 0 {
+		w.WriteHeader(http.StatusOK)
+	}
+	res := events.APIGatewayProxyResponse{
+		StatusCode:        w.status,
+		MultiValueHeaders: map[string][]string{},
+	}
+	if w.header.Get("Content-Length") == "" {
+		w.header.Set("Content-Length", strconv.Itoa(w.body.Len()))
+	}
+	for k, vs := range w.header {
+		res.MultiValueHeaders[k] = vs
+	}
+	b := w.body.Bytes()
+	if w.header.Get("Content-Encoding") != "" || !utf8.Valid(b) {
+		res.Body = base64.StdEncoding.EncodeToString(b)
+		res.IsBase64Encoded = true
+	} else {
+		res.Body = string(b)
+	}
+	return res
+}
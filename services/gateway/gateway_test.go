@@ -0,0 +1,103 @@
+package gateway
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+func loadEvent(t *testing.T, name string) events.APIGatewayProxyRequest {
+	raw, err := ioutil.ReadFile("testdata/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var e events.APIGatewayProxyRequest
+	if err := json.Unmarshal(raw, &e); err != nil {
+		t.Fatal(err)
+	}
+	return e
+}
+
+func TestGateway(t *testing.T) {
+	t.Run("decode base64 request body", func(t *testing.T) {
+		h := NewHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != "POST" || r.URL.Path != "/query" {
+				t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			}
+			if r.Header.Get("X-Request-Id") != "41b45ea3-70b5-11e6-b7bd-69b5aaebc7d9" {
+				t.Errorf("expected request id header")
+			}
+			body, _ := ioutil.ReadAll(r.Body)
+			w.Header().Set("Content-Type", "application/json")
+			w.Write(body)
+		}))
+		res, err := h(context.Background(), loadEvent(t, "post-query.json"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.StatusCode != http.StatusOK {
+			t.Errorf("expected status 200, got %d", res.StatusCode)
+		}
+		if res.IsBase64Encoded {
+			t.Errorf("expected plain text body")
+		}
+		if res.Body != `{"query":"{ viewer(jwt: \"\") { name } }"}` {
+			t.Errorf("unexpected body %s", res.Body)
+		}
+	})
+
+	t.Run("base64 encode gzip response", func(t *testing.T) {
+		h := NewHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Query().Get("v") != "1" {
+				t.Errorf("expected query string")
+			}
+			if r.Header.Get("Accept-Encoding") != "gzip, deflate" {
+				t.Errorf("expected accept encoding header")
+			}
+			w.Header().Set("Content-Encoding", "gzip")
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			gz := gzip.NewWriter(w)
+			gz.Write([]byte("<html></html>"))
+			gz.Close()
+		}))
+		res, err := h(context.Background(), loadEvent(t, "get-static.json"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !res.IsBase64Encoded {
+			t.Fatalf("expected base64 body")
+		}
+		raw, _ := base64.StdEncoding.DecodeString(res.Body)
+		gz, err := gzip.NewReader(bytes.NewReader(raw))
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := ioutil.ReadAll(gz)
+		if string(body) != "<html></html>" {
+			t.Errorf("unexpected body %s", body)
+		}
+		if res.MultiValueHeaders["Content-Encoding"][0] != "gzip" {
+			t.Errorf("expected gzip content encoding")
+		}
+	})
+
+	t.Run("default status and content type", func(t *testing.T) {
+		h := NewHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("<html></html>"))
+		}))
+		res, _ := h(context.Background(), loadEvent(t, "get-static.json"))
+		if res.StatusCode != http.StatusOK {
+			t.Errorf("expected status 200, got %d", res.StatusCode)
+		}
+		if res.MultiValueHeaders["Content-Type"][0] != "text/html; charset=utf-8" {
+			t.Errorf("unexpected content type %v", res.MultiValueHeaders["Content-Type"])
+		}
+	})
+}
@@ -0,0 +1,206 @@
+package graphqlhttp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"go-lambda-graphql/services/apperr"
+	"go-lambda-graphql/services/cost"
+	"go-lambda-graphql/services/persisted"
+	"log"
+	"mime"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/errors"
+)
+
+// Schema runs and validates queries, *graphql.Schema implements it
+type Schema interface {
+	Exec(ctx context.Context, queryString string, operationName string, variables map[string]interface{}) *graphql.Response
+	Validate(queryString string) []*errors.QueryError
+}
+
+// CheckFunc refuses a document before it runs for the caller of ctx, nil lets it through
+type CheckFunc func(ctx context.Context, document string, operationName string, variables map[string]interface{}) *errors.QueryError
+
+// Handler serves graphql over POST and GET, with automatic persisted queries,
+// GET only runs queries so the responses can be cached
+type Handler struct {
+	Schema Schema
+	// Check runs before the schema, on the depth and cost limits or the permissions for example
+	Check CheckFunc
+	// Queries stores the persisted queries, nil turns them off
+	Queries persisted.Store
+	// Register reports whether the caller of ctx may store new persisted queries,
+	// nil lets nobody, the stored queries still run by hash
+	Register func(ctx context.Context) bool
+	// MaxAge is how long the successful responses to GET requests may be cached
+	MaxAge time.Duration
+	// Production hides the message of the internal errors, they are only logged
+	Production bool
+}
+
+type persistedQuery struct {
+	Version    int    `json:"version"`
+	SHA256Hash string `json:"sha256Hash"`
+}
+
+type extensions struct {
+	PersistedQuery *persistedQuery `json:"persistedQuery"`
+}
+
+type params struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+	Extensions    extensions             `json:"extensions"`
+}
+
+// fromQuery reads the parameters of a GET request, variables and extensions are json encoded
+func (p *params) fromQuery(values url.Values) error {
+	p.Query = values.Get("query")
+	p.OperationName = values.Get("operationName")
+	if v := values.Get("variables"); v != "" {
+		if err := json.Unmarshal([]byte(v), &p.Variables); err != nil {
+			return fmt.Errorf("invalid variables: %v", err)
+		}
+	}
+	if v := values.Get("extensions"); v != "" {
+		if err := json.Unmarshal([]byte(v), &p.Extensions); err != nil {
+			return fmt.Errorf("invalid extensions: %v", err)
+		}
+	}
+	return nil
+}
+
+// ServeHTTP runs the query of a request
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var p params
+	switch r.Method {
+	case http.MethodGet:
+		if err := p.fromQuery(r.URL.Query()); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	case http.MethodPost:
+		// a form or a text/plain body could be posted by any site without a preflight
+		if t, _, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err != nil || t != "application/json" {
+			http.Error(w, "the body must be application/json", http.StatusUnsupportedMediaType)
+			return
+		}
+		if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	correlationID := apperr.CorrelationID(r.Header.Get("X-Request-Id"))
+	w.Header().Set("X-Request-Id", correlationID)
+	query, queryErr := h.resolve(r.Context(), &p)
+	if queryErr == nil && h.Check != nil {
+		queryErr = h.Check(r.Context(), query, p.OperationName, p.Variables)
+	}
+	operationType := cost.OperationType(query, p.OperationName)
+	if queryErr == nil && operationType == "" {
+		queryErr = unreadableError(h.Schema, query)
+	}
+	if queryErr != nil {
+		apperr.Mask([]*errors.QueryError{queryErr}, correlationID, h.Production)
+		// apollo clients expect the persisted query errors with a 200 status, like any other graphql error
+		write(w, &graphql.Response{Errors: []*errors.QueryError{queryErr}}, "no-store")
+		return
+	}
+	if r.Method == http.MethodGet && operationType != "query" {
+		// mutations change state, a link or a cache must not be able to trigger them
+		w.Header().Set("Allow", "POST")
+		http.Error(w, "only queries can be sent with GET", http.StatusMethodNotAllowed)
+		return
+	}
+
+	response := h.Schema.Exec(r.Context(), query, p.OperationName, p.Variables)
+	apperr.Mask(response.Errors, correlationID, h.Production)
+	cacheControl := "no-store"
+	if r.Method == http.MethodGet && len(response.Errors) == 0 && h.MaxAge > 0 {
+		// a response depending on the caller must stay in the browser, shared caches only keep anonymous ones
+		scope := "public"
+		if r.Header.Get("Authorization") != "" || len(r.Cookies()) > 0 {
+			scope = "private"
+		}
+		cacheControl = fmt.Sprintf("%s, max-age=%d", scope, int(h.MaxAge/time.Second))
+		w.Header().Set("Vary", "Authorization, Cookie")
+	}
+	write(w, response, cacheControl)
+}
+
+// resolve returns the text of the query, looking it up or storing it when the request carries a persisted query hash
+func (h *Handler) resolve(ctx context.Context, p *params) (string, *errors.QueryError) {
+	pq := p.Extensions.PersistedQuery
+	if pq == nil {
+		return p.Query, nil
+	}
+	if h.Queries == nil || pq.Version != 1 {
+		return "", persistedError("PersistedQueryNotSupported", "PERSISTED_QUERY_NOT_SUPPORTED")
+	}
+	if p.Query == "" {
+		query, err := h.Queries.Get(pq.SHA256Hash)
+		switch err {
+		case nil:
+			return query, nil
+		case persisted.ErrNotFound:
+			// the client retries with the full query, which stores it
+			return "", persistedError("PersistedQueryNotFound", "PERSISTED_QUERY_NOT_FOUND")
+		case persisted.ErrInvalidHash:
+			return "", persistedError(err.Error(), "PERSISTED_QUERY_INVALID_HASH")
+		default:
+			log.Println("persisted query:", err)
+			return "", persistedError("persisted queries are unavailable", "PERSISTED_QUERY_NOT_SUPPORTED")
+		}
+	}
+	if persisted.Hash(p.Query) != pq.SHA256Hash {
+		return "", persistedError("provided sha does not match query", "PERSISTED_QUERY_HASH_MISMATCH")
+	}
+	// only the callers allowed to register store queries, and only the valid ones within the limits,
+	// the store cannot be filled with garbage
+	if h.Register == nil || !h.Register(ctx) {
+		return p.Query, nil
+	}
+	if len(h.Schema.Validate(p.Query)) == 0 && (h.Check == nil || h.Check(ctx, p.Query, p.OperationName, p.Variables) == nil) {
+		switch err := h.Queries.Put(pq.SHA256Hash, p.Query); err {
+		case nil, persisted.ErrTooLarge, persisted.ErrReadOnly:
+		default:
+			log.Println("persisted query:", err)
+		}
+	}
+	return p.Query, nil
+}
+
+// unreadableError refuses a document whose operation cannot be read, graphql-go may read one
+// the checks did not see, the validation of the schema tells what is wrong with an invalid document
+func unreadableError(schema Schema, query string) *errors.QueryError {
+	if errs := schema.Validate(query); len(errs) > 0 {
+		return errs[0]
+	}
+	return &errors.QueryError{Message: "the operation of the query cannot be read", Extensions: map[string]interface{}{"code": cost.Unreadable}}
+}
+
+func persistedError(message string, code string) *errors.QueryError {
+	return &errors.QueryError{Message: message, Extensions: map[string]interface{}{"code": code}}
+}
+
+func write(w http.ResponseWriter, response *graphql.Response, cacheControl string) {
+	responseJSON, err := json.Marshal(response)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", cacheControl)
+	w.Write(responseJSON)
+}
@@ -0,0 +1,204 @@
+package graphqlhttp
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"go-lambda-graphql/services/persisted"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/errors"
+)
+
+// echo answers every query with its text, queries containing invalid do not validate
+// and queries containing broken fail like a database error
+type echo struct{}
+
+func (echo) Exec(ctx context.Context, queryString string, operationName string, variables map[string]interface{}) *graphql.Response {
+	if strings.Contains(queryString, "broken") {
+		return &graphql.Response{Errors: []*errors.QueryError{{Message: "pq: connection refused", ResolverError: sql.ErrConnDone}}}
+	}
+	data, _ := json.Marshal(map[string]interface{}{"query": queryString, "variables": variables})
+	return &graphql.Response{Data: data}
+}
+
+func (echo) Validate(queryString string) []*errors.QueryError {
+	if strings.Contains(queryString, "invalid") {
+		return []*errors.QueryError{errors.Errorf("invalid query")}
+	}
+	return nil
+}
+
+// authorizedKey marks the test requests allowed to register persisted queries
+type authorizedKey struct{}
+
+func TestHandler(t *testing.T) {
+	dir, err := ioutil.TempDir("", "graphqlhttp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	// the callers sending an Authorization header may register queries
+	register := func(ctx context.Context) bool {
+		return ctx.Value(authorizedKey{}) != nil
+	}
+	h := &Handler{Schema: echo{}, Queries: &persisted.FileStore{Dir: dir}, Register: register, MaxAge: time.Minute}
+	query := "{ viewer { id } }"
+	hash := persisted.Hash(query)
+	extensions := `{"persistedQuery":{"version":1,"sha256Hash":"` + hash + `"}}`
+
+	post := func(h http.Handler, body string, authorized bool) *httptest.ResponseRecorder {
+		r := httptest.NewRequest("POST", "/query", strings.NewReader(body))
+		r.Header.Set("Content-Type", "application/json; charset=utf-8")
+		if authorized {
+			r = r.WithContext(context.WithValue(r.Context(), authorizedKey{}, true))
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+	get := func(values url.Values, header http.Header) (*httptest.ResponseRecorder, map[string]interface{}) {
+		r := httptest.NewRequest("GET", "/query?"+values.Encode(), nil)
+		for k, v := range header {
+			r.Header[k] = v
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		var body map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &body)
+		return w, body
+	}
+	code := func(body map[string]interface{}) interface{} {
+		errs, _ := body["errors"].([]interface{})
+		if len(errs) == 0 {
+			return nil
+		}
+		return errs[0].(map[string]interface{})["extensions"].(map[string]interface{})["code"]
+	}
+
+	t.Run("run queries sent with GET", func(t *testing.T) {
+		w, body := get(url.Values{"query": {query}, "variables": {`{"a":1}`}}, nil)
+		data, _ := body["data"].(map[string]interface{})
+		if w.Code != 200 || data["query"] != query || data["variables"].(map[string]interface{})["a"] != 1.0 {
+			t.Errorf("unexpected response %d %s", w.Code, w.Body)
+		}
+		if cc := w.Header().Get("Cache-Control"); cc != "public, max-age=60" {
+			t.Errorf("unexpected cache control %s", cc)
+		}
+		w, _ = get(url.Values{"query": {query}}, http.Header{"Authorization": {"Bearer token"}})
+		if cc := w.Header().Get("Cache-Control"); cc != "private, max-age=60" {
+			t.Errorf("unexpected cache control %s", cc)
+		}
+	})
+
+	t.Run("refuse mutations sent with GET", func(t *testing.T) {
+		if w, _ := get(url.Values{"query": {"mutation { logout }"}}, nil); w.Code != http.StatusMethodNotAllowed {
+			t.Errorf("unexpected status %d", w.Code)
+		}
+	})
+
+	t.Run("handshake unknown hashes", func(t *testing.T) {
+		_, body := get(url.Values{"extensions": {extensions}}, nil)
+		if code(body) != "PERSISTED_QUERY_NOT_FOUND" {
+			t.Errorf("unexpected response %v", body)
+		}
+		registerBody := `{"query":"` + query + `","extensions":` + extensions + `}`
+		w := post(h, registerBody, false)
+		if w.Code != 200 || !strings.Contains(w.Body.String(), "viewer") {
+			t.Errorf("unexpected response %d %s", w.Code, w.Body)
+		}
+		if _, err := h.Queries.Get(hash); err != persisted.ErrNotFound {
+			t.Errorf("expected an anonymous caller not to register the query, got %v", err)
+		}
+		post(h, registerBody, true)
+		_, body = get(url.Values{"extensions": {extensions}}, nil)
+		if data, _ := body["data"].(map[string]interface{}); data["query"] != query {
+			t.Errorf("unexpected response %v", body)
+		}
+	})
+
+	t.Run("reject mismatching and invalid queries", func(t *testing.T) {
+		_, body := get(url.Values{"query": {"{ other }"}, "extensions": {extensions}}, nil)
+		if code(body) != "PERSISTED_QUERY_HASH_MISMATCH" {
+			t.Errorf("unexpected response %v", body)
+		}
+		invalid := "{ invalid }"
+		get(url.Values{"query": {invalid}, "extensions": {`{"persistedQuery":{"version":1,"sha256Hash":"` + persisted.Hash(invalid) + `"}}`}}, nil)
+		if _, err := h.Queries.Get(persisted.Hash(invalid)); err != persisted.ErrNotFound {
+			t.Errorf("expected the invalid query not to be stored, got %v", err)
+		}
+	})
+
+	t.Run("check before running", func(t *testing.T) {
+		h := &Handler{Schema: echo{}, Queries: h.Queries, Register: register, Check: func(ctx context.Context, document string, operationName string, variables map[string]interface{}) *errors.QueryError {
+			if strings.Contains(document, "deep") {
+				return &errors.QueryError{Message: "too deep", Extensions: map[string]interface{}{"code": "QUERY_TOO_DEEP"}}
+			}
+			return nil
+		}}
+		deep := "{ deep }"
+		w := post(h, `{"query":"`+deep+`","extensions":{"persistedQuery":{"version":1,"sha256Hash":"`+persisted.Hash(deep)+`"}}}`, true)
+		if !strings.Contains(w.Body.String(), "QUERY_TOO_DEEP") || strings.Contains(w.Body.String(), "data") {
+			t.Errorf("unexpected response %s", w.Body)
+		}
+		if _, err := h.Queries.Get(persisted.Hash(deep)); err != persisted.ErrNotFound {
+			t.Errorf("expected the refused query not to be stored, got %v", err)
+		}
+	})
+
+	t.Run("refuse operations that cannot be read", func(t *testing.T) {
+		h := &Handler{Schema: echo{}}
+		w := post(h, `{"query":"query A { a } query B { b }"}`, true)
+		if !strings.Contains(w.Body.String(), "QUERY_UNREADABLE") || strings.Contains(w.Body.String(), "data") {
+			t.Errorf("unexpected response %s", w.Body)
+		}
+		if w, _ := get(url.Values{"query": {"# x\rmutation { logout }"}}, nil); w.Code != http.StatusMethodNotAllowed {
+			t.Errorf("expected a mutation after a comment to be refused over GET, got %d", w.Code)
+		}
+	})
+
+	t.Run("mask internal errors", func(t *testing.T) {
+		h := &Handler{Schema: echo{}, Production: true}
+		r := httptest.NewRequest("POST", "/query", strings.NewReader(`{"query":"{ broken }"}`))
+		r.Header.Set("Content-Type", "application/json")
+		r.Header.Set("X-Request-Id", "req-1")
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		body := w.Body.String()
+		if strings.Contains(body, "pq:") || !strings.Contains(body, `"code":"INTERNAL"`) || !strings.Contains(body, `"correlationId":"req-1"`) {
+			t.Errorf("unexpected response %s", body)
+		}
+		if id := w.Header().Get("X-Request-Id"); id != "req-1" {
+			t.Errorf("unexpected correlation id %s", id)
+		}
+	})
+
+	t.Run("only decode json bodies", func(t *testing.T) {
+		for _, contentType := range []string{"", "text/plain", "application/x-www-form-urlencoded"} {
+			r := httptest.NewRequest("POST", "/query", strings.NewReader(`{"query":"`+query+`"}`))
+			r.Header.Set("Content-Type", contentType)
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+			if w.Code != http.StatusUnsupportedMediaType {
+				t.Errorf("%q: unexpected status %d", contentType, w.Code)
+			}
+		}
+	})
+
+	t.Run("report disabled persisted queries", func(t *testing.T) {
+		h := &Handler{Schema: echo{}}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("GET", "/query?"+url.Values{"extensions": {extensions}}.Encode(), nil))
+		if !strings.Contains(w.Body.String(), "PERSISTED_QUERY_NOT_SUPPORTED") {
+			t.Errorf("unexpected response %s", w.Body)
+		}
+	})
+}
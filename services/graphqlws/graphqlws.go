@@ -0,0 +1,249 @@
+package graphqlws
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"go-lambda-graphql/services/apperr"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/graph-gophers/graphql-go"
+	qerrors "github.com/graph-gophers/graphql-go/errors"
+)
+
+// Protocol is the websocket subprotocol of the apollo subscriptions-transport-ws clients
+const Protocol = "graphql-ws"
+
+// the message types of the protocol
+const (
+	connectionInit      = "connection_init"
+	connectionAck       = "connection_ack"
+	connectionError     = "connection_error"
+	connectionKeepAlive = "ka"
+	connectionTerminate = "connection_terminate"
+	start               = "start"
+	data                = "data"
+	errorType           = "error"
+	complete            = "complete"
+	stop                = "stop"
+)
+
+// writeTimeout is how long a client has to take a message before it is disconnected
+const writeTimeout = 10 * time.Second
+
+// Subscriber runs a query, mutation or subscription, sending every response on the channel,
+// *graphql.Schema implements it
+type Subscriber interface {
+	Subscribe(ctx context.Context, queryString string, operationName string, variables map[string]interface{}) (<-chan interface{}, error)
+}
+
+// InitFunc authenticates a connection from the payload of its connection_init message,
+// the context it returns is the parent of every operation of the connection
+type InitFunc func(ctx context.Context, payload json.RawMessage) (context.Context, error)
+
+// CheckFunc refuses a document before it runs for the caller of ctx, nil lets it through
+type CheckFunc func(ctx context.Context, document string, operationName string, variables map[string]interface{}) *qerrors.QueryError
+
+// Handler serves graphql over websockets, the http server timeouts do not apply once the connection is upgraded
+type Handler struct {
+	Schema Subscriber
+	Init   InitFunc
+	// Check runs before every operation, on the depth and cost limits or the permissions for example
+	Check CheckFunc
+	// KeepAlive is how often the client is pinged, a client missing two pings is disconnected
+	KeepAlive time.Duration
+	// Production hides the message of the internal errors, they are only logged
+	Production bool
+}
+
+type message struct {
+	ID      string          `json:"id,omitempty"`
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+type startPayload struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// errorPayload is the payload of connection_error and error messages
+type errorPayload struct {
+	Message string `json:"message"`
+}
+
+var upgrader = websocket.Upgrader{Subprotocols: []string{Protocol}}
+
+// conn is a single websocket connection, writes come from every running operation so they are serialized
+type conn struct {
+	ws     *websocket.Conn
+	writes sync.Mutex
+	mu     sync.Mutex
+	ops    map[string]context.CancelFunc
+}
+
+// ServeHTTP upgrades the request and runs the protocol until the client disconnects or terminates
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ws, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// Upgrade already replied with an http error
+		return
+	}
+	defer ws.Close()
+	if ws.Subprotocol() != Protocol {
+		ws.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseProtocolError, "expected the "+Protocol+" subprotocol"), time.Now().Add(writeTimeout))
+		return
+	}
+	keepAlive := h.KeepAlive
+	if keepAlive <= 0 {
+		keepAlive = 30 * time.Second
+	}
+	c := &conn{ws: ws, ops: map[string]context.CancelFunc{}}
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	ws.SetReadDeadline(time.Now().Add(2 * keepAlive))
+	ws.SetPongHandler(func(string) error {
+		return ws.SetReadDeadline(time.Now().Add(2 * keepAlive))
+	})
+	go c.ping(ctx, keepAlive)
+
+	var opCtx context.Context
+	for {
+		var msg message
+		if err := ws.ReadJSON(&msg); err != nil {
+			return
+		}
+		ws.SetReadDeadline(time.Now().Add(2 * keepAlive))
+		switch msg.Type {
+		case connectionInit:
+			if opCtx != nil {
+				c.close(websocket.CloseProtocolError, "connection already initialized")
+				return
+			}
+			opCtx = ctx
+			if h.Init != nil {
+				if opCtx, err = h.Init(ctx, msg.Payload); err != nil {
+					c.send(message{Type: connectionError, Payload: marshal(errorPayload{Message: err.Error()})})
+					return
+				}
+			}
+			c.send(message{Type: connectionAck})
+			c.send(message{Type: connectionKeepAlive})
+		case start:
+			if opCtx == nil {
+				c.close(websocket.CloseProtocolError, "connection not initialized")
+				return
+			}
+			c.start(opCtx, h, msg)
+		case stop:
+			c.stop(msg.ID)
+		case connectionTerminate:
+			return
+		default:
+			c.send(message{ID: msg.ID, Type: errorType, Payload: marshal(errorPayload{Message: "unknown message type " + msg.Type})})
+		}
+	}
+}
+
+// start runs an operation until it completes, the client stops it or the connection closes
+func (c *conn) start(ctx context.Context, h *Handler, msg message) {
+	var payload startPayload
+	err := json.Unmarshal(msg.Payload, &payload)
+	if err == nil && msg.ID == "" {
+		err = errors.New("missing operation id")
+	}
+	if err != nil {
+		c.send(message{ID: msg.ID, Type: errorType, Payload: marshal(errorPayload{Message: err.Error()})})
+		return
+	}
+	correlationID := apperr.CorrelationID("")
+	if h.Check != nil {
+		if queryErr := h.Check(ctx, payload.Query, payload.OperationName, payload.Variables); queryErr != nil {
+			apperr.Mask([]*qerrors.QueryError{queryErr}, correlationID, h.Production)
+			c.send(message{ID: msg.ID, Type: errorType, Payload: marshal(queryErr)})
+			return
+		}
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	c.mu.Lock()
+	if _, ok := c.ops[msg.ID]; ok {
+		c.mu.Unlock()
+		cancel()
+		c.send(message{ID: msg.ID, Type: errorType, Payload: marshal(errorPayload{Message: "operation " + msg.ID + " is already running"})})
+		return
+	}
+	c.ops[msg.ID] = cancel
+	c.mu.Unlock()
+
+	responses, err := h.Schema.Subscribe(ctx, payload.Query, payload.OperationName, payload.Variables)
+	if err != nil {
+		c.stop(msg.ID)
+		c.send(message{ID: msg.ID, Type: errorType, Payload: marshal(errorPayload{Message: err.Error()})})
+		return
+	}
+	go func() {
+		for response := range responses {
+			if r, ok := response.(*graphql.Response); ok {
+				apperr.Mask(r.Errors, correlationID, h.Production)
+			}
+			c.send(message{ID: msg.ID, Type: data, Payload: marshal(response)})
+		}
+		// a stopped operation is not completed, the client already forgot about it
+		if c.stop(msg.ID) {
+			c.send(message{ID: msg.ID, Type: complete})
+		}
+	}()
+}
+
+// stop cancels an operation, it reports whether the operation was still running
+func (c *conn) stop(id string) bool {
+	c.mu.Lock()
+	cancel, ok := c.ops[id]
+	delete(c.ops, id)
+	c.mu.Unlock()
+	if ok {
+		cancel()
+	}
+	return ok
+}
+
+// ping keeps the connection alive through proxies and notices clients that went away
+func (c *conn) ping(ctx context.Context, keepAlive time.Duration) {
+	t := time.NewTicker(keepAlive)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			if err := c.ws.WriteControl(websocket.PingMessage, nil, time.Now().Add(writeTimeout)); err != nil {
+				return
+			}
+			c.send(message{Type: connectionKeepAlive})
+		}
+	}
+}
+
+func (c *conn) send(msg message) {
+	c.writes.Lock()
+	defer c.writes.Unlock()
+	c.ws.SetWriteDeadline(time.Now().Add(writeTimeout))
+	// a failed write breaks the connection, the read loop notices and tears everything down
+	c.ws.WriteJSON(msg)
+}
+
+func (c *conn) close(code int, text string) {
+	c.ws.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, text), time.Now().Add(writeTimeout))
+}
+
+func marshal(v interface{}) json.RawMessage {
+	b, err := json.Marshal(v)
+	if err != nil {
+		b, _ = json.Marshal(errorPayload{Message: err.Error()})
+	}
+	return b
+}
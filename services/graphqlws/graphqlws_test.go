@@ -0,0 +1,143 @@
+package graphqlws
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// ticker sends a response per tick until its context is done
+type ticker struct{}
+
+func (ticker) Subscribe(ctx context.Context, queryString string, operationName string, variables map[string]interface{}) (<-chan interface{}, error) {
+	if queryString == "fail" {
+		return nil, errors.New("cannot subscribe")
+	}
+	c := make(chan interface{})
+	go func() {
+		defer close(c)
+		for i := 0; ; i++ {
+			if queryString == "once" && i == 1 {
+				return
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case c <- map[string]interface{}{"tick": i, "user": ctx.Value(userKey{})}:
+			}
+		}
+	}()
+	return c, nil
+}
+
+type userKey struct{}
+
+func dial(t *testing.T, url string, subprotocol string) *websocket.Conn {
+	d := websocket.Dialer{Subprotocols: []string{subprotocol}}
+	ws, _, err := d.Dial("ws"+strings.TrimPrefix(url, "http"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ws.SetReadDeadline(time.Now().Add(5 * time.Second))
+	return ws
+}
+
+func read(t *testing.T, ws *websocket.Conn, types ...string) message {
+	for {
+		var msg message
+		if err := ws.ReadJSON(&msg); err != nil {
+			t.Fatal(err)
+		}
+		for _, typ := range types {
+			if msg.Type == typ {
+				return msg
+			}
+		}
+		if msg.Type != connectionKeepAlive {
+			t.Fatalf("expected %v, got %s %s", types, msg.Type, msg.Payload)
+		}
+	}
+}
+
+func TestHandler(t *testing.T) {
+	server := httptest.NewServer(&Handler{
+		Schema: ticker{},
+		Init: func(ctx context.Context, payload json.RawMessage) (context.Context, error) {
+			var p struct {
+				AuthToken string `json:"authToken"`
+			}
+			json.Unmarshal(payload, &p)
+			if p.AuthToken == "" {
+				return nil, errors.New("unauthenticated")
+			}
+			return context.WithValue(ctx, userKey{}, p.AuthToken), nil
+		},
+	})
+	defer server.Close()
+
+	t.Run("reject connections without the subprotocol", func(t *testing.T) {
+		ws := dial(t, server.URL, "other")
+		defer ws.Close()
+		if _, _, err := ws.ReadMessage(); !websocket.IsCloseError(err, websocket.CloseProtocolError) {
+			t.Errorf("expected a protocol error close, got %v", err)
+		}
+	})
+
+	t.Run("reject failed init", func(t *testing.T) {
+		ws := dial(t, server.URL, Protocol)
+		defer ws.Close()
+		ws.WriteJSON(message{Type: connectionInit})
+		msg := read(t, ws, connectionError)
+		if !strings.Contains(string(msg.Payload), "unauthenticated") {
+			t.Errorf("unexpected payload %s", msg.Payload)
+		}
+	})
+
+	t.Run("stream until stopped", func(t *testing.T) {
+		ws := dial(t, server.URL, Protocol)
+		defer ws.Close()
+		ws.WriteJSON(message{Type: connectionInit, Payload: json.RawMessage(`{"authToken":"alice"}`)})
+		read(t, ws, connectionAck)
+		ws.WriteJSON(message{ID: "1", Type: start, Payload: json.RawMessage(`{"query":"subscription"}`)})
+		for i := 0; i < 3; i++ {
+			msg := read(t, ws, data)
+			if msg.ID != "1" || !strings.Contains(string(msg.Payload), `"user":"alice"`) {
+				t.Errorf("unexpected message %s %s", msg.ID, msg.Payload)
+			}
+		}
+		ws.WriteJSON(message{ID: "1", Type: stop})
+		ws.WriteJSON(message{ID: "2", Type: start, Payload: json.RawMessage(`{"query":"once"}`)})
+		// responses of the stopped operation may still be in flight
+		for {
+			if msg := read(t, ws, data, complete); msg.Type == complete {
+				if msg.ID != "2" {
+					t.Errorf("unexpected completion of %s", msg.ID)
+				}
+				break
+			}
+		}
+	})
+
+	t.Run("report failed operations", func(t *testing.T) {
+		ws := dial(t, server.URL, Protocol)
+		defer ws.Close()
+		ws.WriteJSON(message{ID: "1", Type: start, Payload: json.RawMessage(`{"query":"once"}`)})
+		if _, _, err := ws.ReadMessage(); !websocket.IsCloseError(err, websocket.CloseProtocolError) {
+			t.Errorf("expected a protocol error close before init, got %v", err)
+		}
+		ws = dial(t, server.URL, Protocol)
+		defer ws.Close()
+		ws.WriteJSON(message{Type: connectionInit, Payload: json.RawMessage(`{"authToken":"bob"}`)})
+		read(t, ws, connectionAck)
+		ws.WriteJSON(message{ID: "1", Type: start, Payload: json.RawMessage(`{"query":"fail"}`)})
+		if msg := read(t, ws, errorType); msg.ID != "1" || !strings.Contains(string(msg.Payload), "cannot subscribe") {
+			t.Errorf("unexpected message %s %s", msg.ID, msg.Payload)
+		}
+	})
+}
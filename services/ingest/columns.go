@@ -0,0 +1,132 @@
+package ingest
+
+// Kind is the postgres type of a submittal_log_facts column
+type Kind int
+
+const (
+	// Text is a text column
+	Text Kind = iota
+	// BigInt is a bigint column
+	BigInt
+	// Integer is an integer column
+	Integer
+	// Boolean is a boolean column
+	Boolean
+	// Date is a date column
+	Date
+	// Timestamp is a timestamp without time zone column
+	Timestamp
+	// JSON is a jsonb column
+	JSON
+	// TextArray is a text[] column
+	TextArray
+	// BigIntArray is a bigint[] column
+	BigIntArray
+	// DateArray is a date[] column, elements can be null to keep the approver arrays aligned
+	DateArray
+)
+
+// Column is an importable column of submittal_log_facts
+type Column struct {
+	Name string
+	Kind Kind
+}
+
+// Columns lists every importable column in table order, the id is always assigned by the database
+var Columns = []Column{
+	{"company_id", BigInt},
+	{"cost_code_id", BigInt},
+	{"created_by_id", BigInt},
+	{"location_id", BigInt},
+	{"project_id", BigInt},
+	{"received_from_id", BigInt},
+	{"responsible_contractor_id", BigInt},
+	{"specification_section_id", BigInt},
+	{"submittal_log_id", BigInt},
+	{"submittal_log_status_id", BigInt},
+	{"submittal_manager_id", BigInt},
+	{"submittal_package_id", BigInt},
+	{"cost_code", Text},
+	{"created_by", Text},
+	{"custom_textarea_1", Text},
+	{"custom_textfield_1", Text},
+	{"deleted_at", Timestamp},
+	{"date_received", Date},
+	{"date_required_on_site", Date},
+	{"date_issue", Date},
+	{"date_created_at", Date},
+	{"date_submit_by", Date},
+	{"date_distributed", Date},
+	{"date_final_due", Date},
+	{"date_actual_delivery", Date},
+	{"date_confirmed_delivery", Date},
+	{"date_anticipated_delivery", Date},
+	{"description", Text},
+	{"design_team_review_time", Integer},
+	{"internal_review_time", Integer},
+	{"lead_time", Integer},
+	{"location", Text},
+	{"number", Text},
+	{"package_name", Text},
+	{"package_number", Text},
+	{"planned_internal_review_completed_date", Date},
+	{"planned_return_date", Date},
+	{"planned_submit_by_date", Date},
+	{"project_address", Text},
+	{"project_bid_type", Text},
+	{"project_city", Text},
+	{"project_county", Text},
+	{"project_date_created", Date},
+	{"project_department", JSON},
+	{"project_estimated_start_date", Date},
+	{"project_estimated_completion_date", Date},
+	{"project_description", Text},
+	{"project_designated_market_area", Text},
+	{"project_name", Text},
+	{"project_notes", Text},
+	{"project_number", Text},
+	{"project_office", Text},
+	{"project_owner_type", Text},
+	{"project_parent_job", Text},
+	{"project_phone", Text},
+	{"project_program", Text},
+	{"project_square_feet", Integer},
+	{"project_region", Text},
+	{"project_stage", Text},
+	{"project_state", Text},
+	{"project_type", Text},
+	{"project_zip", Text},
+	{"private", Boolean},
+	{"received_from", Text},
+	{"responsible_contractor", Text},
+	{"revision", Text},
+	{"scheduled_task", Text},
+	{"spec_section_description", Text},
+	{"spec_section_number", Text},
+	{"status", Text},
+	{"status_name", Text},
+	{"submittal_manager", Text},
+	{"submittal_type", Text},
+	{"title", Text},
+	{"created_at", Timestamp},
+	{"updated_at", Timestamp},
+	{"approver_names", TextArray},
+	{"approver_ids", BigIntArray},
+	{"approver_vendor_ids", BigIntArray},
+	{"approver_responses", TextArray},
+	{"approver_sent_dates", DateArray},
+	{"approver_returned_dates", DateArray},
+	{"approver_due_dates", DateArray},
+	{"responsed_vendor_ids", BigIntArray},
+	{"ball_in_court_names", TextArray},
+	{"ball_in_court_ids", BigIntArray},
+	{"ball_in_court_due_date", DateArray},
+}
+
+var columnsByName = map[string]Column{}
+
+func init() {
+	for _, c := range Columns {
+		columnsByName[c.Name] = c
+	}
+}
@@ -0,0 +1,172 @@
+package ingest
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const (
+	dateFormat      = "2006-01-02"
+	timestampFormat = "2006-01-02 15:04:05.999999"
+)
+
+// timeLayouts are the date and time formats accepted in exports
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999",
+	dateFormat,
+	"01/02/2006",
+}
+
+// convert turns a value read from an export into the text copied into the column, nil is null.
+// Array columns are never null, a missing array is copied as an empty one like the column default
+func (c Column) convert(v interface{}) (interface{}, error) {
+	switch c.Kind {
+	case TextArray, BigIntArray, DateArray:
+		return c.convertArray(v)
+	}
+	if v == nil {
+		return nil, nil
+	}
+	if c.Kind == JSON {
+		if s, ok := v.(string); ok {
+			if !json.Valid([]byte(s)) {
+				return nil, errors.New("invalid json")
+			}
+			return s, nil
+		}
+		raw, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		return string(raw), nil
+	}
+	return scalar(c.Kind, v)
+}
+
+func (c Column) convertArray(v interface{}) (interface{}, error) {
+	elems, err := arrayElems(v)
+	if err != nil {
+		return nil, err
+	}
+	kind := Text
+	switch c.Kind {
+	case BigIntArray:
+		kind = BigInt
+	case DateArray:
+		kind = Date
+	}
+	l := make([]sql.NullString, len(elems))
+	for i, e := range elems {
+		if e == nil {
+			if c.Kind != DateArray {
+				return nil, fmt.Errorf("null at index %d", i)
+			}
+			continue
+		}
+		s, err := scalar(kind, e)
+		if err != nil {
+			return nil, fmt.Errorf("index %d: %v", i, err)
+		}
+		l[i] = sql.NullString{String: s, Valid: true}
+	}
+	return pq.Array(l), nil
+}
+
+// arrayElems reads an array given as a json array, or in a csv cell as a json or postgres array literal
+func arrayElems(v interface{}) ([]interface{}, error) {
+	switch v := v.(type) {
+	case nil:
+		return nil, nil
+	case []interface{}:
+		return v, nil
+	case string:
+		s := strings.TrimSpace(v)
+		switch {
+		case s == "":
+			return nil, nil
+		case strings.HasPrefix(s, "["):
+			var elems []interface{}
+			d := json.NewDecoder(strings.NewReader(s))
+			d.UseNumber()
+			if err := d.Decode(&elems); err != nil {
+				return nil, errors.New("invalid json array")
+			}
+			return elems, nil
+		case strings.HasPrefix(s, "{"):
+			var strs []sql.NullString
+			if err := pq.Array(&strs).Scan([]byte(s)); err != nil {
+				return nil, errors.New("invalid array literal")
+			}
+			elems := make([]interface{}, len(strs))
+			for i, str := range strs {
+				if str.Valid {
+					elems[i] = str.String
+				}
+			}
+			return elems, nil
+		}
+	}
+	return nil, errors.New("expected an array")
+}
+
+// scalar validates a single value for kind and returns its postgres text form
+func scalar(kind Kind, v interface{}) (string, error) {
+	var s string
+	switch v := v.(type) {
+	case string:
+		s = strings.TrimSpace(v)
+	case json.Number:
+		s = v.String()
+	case bool:
+		s = strconv.FormatBool(v)
+	default:
+		return "", fmt.Errorf("unexpected %T", v)
+	}
+	switch kind {
+	case BigInt, Integer:
+		bits := 64
+		if kind == Integer {
+			bits = 32
+		}
+		i, err := strconv.ParseInt(s, 10, bits)
+		if err != nil {
+			return "", fmt.Errorf("invalid integer %q", s)
+		}
+		return strconv.FormatInt(i, 10), nil
+	case Boolean:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return "", fmt.Errorf("invalid boolean %q", s)
+		}
+		return strconv.FormatBool(b), nil
+	case Date, Timestamp:
+		t, err := parseTime(s)
+		if err != nil {
+			return "", err
+		}
+		if kind == Date {
+			return t.Format(dateFormat), nil
+		}
+		return t.UTC().Format(timestampFormat), nil
+	}
+	return s, nil
+}
+
+func parseTime(s string) (time.Time, error) {
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q", s)
+}
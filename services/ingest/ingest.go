@@ -0,0 +1,150 @@
+package ingest
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// RowError is a record that was skipped
+type RowError struct {
+	Row    int
+	Column string
+	Err    error
+}
+
+func (e *RowError) Error() string {
+	if e.Column == "" {
+		return fmt.Sprintf("row %d: %v", e.Row, e.Err)
+	}
+	return fmt.Sprintf("row %d: %s: %v", e.Row, e.Column, e.Err)
+}
+
+// Report is the outcome of an import
+type Report struct {
+	// Rows is the number of records read
+	Rows int
+	// Imported is the number of submittals inserted or updated
+	Imported int
+	// Errors lists the records that were skipped
+	Errors []*RowError
+}
+
+const importTable = "submittal_log_facts_import"
+
+// errMissingKey is returned for records that cannot be upserted
+var errMissingKey = errors.New("submittal_log_id is required")
+
+// Import copies every record of r into submittal_log_facts in a single transaction, upserting on submittal_log_id.
+// Records are full rows, missing columns are null. Records that do not convert are skipped and reported,
+// when a submittal_log_id repeats the last record wins. The id column of an export is ignored.
+func Import(db *sql.DB, r Reader) (*Report, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	if _, err := tx.Exec("create temp table " + importTable +
+		" (like submittal_log_facts including defaults, import_row integer not null) on commit drop"); err != nil {
+		return nil, err
+	}
+	names := make([]string, len(Columns), len(Columns)+1)
+	for i, c := range Columns {
+		names[i] = c.Name
+	}
+	stmt, err := tx.Prepare(pq.CopyIn(importTable, append(names, "import_row")...))
+	if err != nil {
+		return nil, err
+	}
+	report := &Report{}
+	for {
+		row, rec, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if bad, ok := err.(*badRecordError); ok {
+			report.Rows++
+			report.Errors = append(report.Errors, &RowError{Row: row, Err: bad.err})
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		report.Rows++
+		values, rowErr := convert(rec)
+		if rowErr != nil {
+			rowErr.Row = row
+			report.Errors = append(report.Errors, rowErr)
+			continue
+		}
+		if _, err := stmt.Exec(append(values, row)...); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := stmt.Exec(); err != nil {
+		return nil, err
+	}
+	if err := stmt.Close(); err != nil {
+		return nil, err
+	}
+	res, err := tx.Exec(upsertSQL(names))
+	if err != nil {
+		return nil, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	report.Imported = int(n)
+	return report, tx.Commit()
+}
+
+// convert maps a record to the values copied for Columns
+func convert(rec Record) ([]interface{}, *RowError) {
+	var unknown []string
+	for name := range rec {
+		if _, ok := columnsByName[name]; !ok && name != "id" {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, &RowError{Column: unknown[0], Err: errors.New("unknown column")}
+	}
+	values := make([]interface{}, len(Columns), len(Columns)+1)
+	for i, c := range Columns {
+		v, err := c.convert(rec[c.Name])
+		if err != nil {
+			return nil, &RowError{Column: c.Name, Err: err}
+		}
+		values[i] = v
+	}
+	if rec["submittal_log_id"] == nil {
+		return nil, &RowError{Column: "submittal_log_id", Err: errMissingKey}
+	}
+	return values, nil
+}
+
+// upsertSQL moves the copied rows into submittal_log_facts, existing rows keep their id,
+// and their deleted_at once set, an export predating a soft delete must not restore the submittal
+func upsertSQL(names []string) string {
+	quoted := make([]string, len(names))
+	sets := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = pq.QuoteIdentifier(name)
+		sets[i] = quoted[i] + " = excluded." + quoted[i]
+		if name == "deleted_at" {
+			sets[i] = quoted[i] + " = coalesce(submittal_log_facts." + quoted[i] + ", excluded." + quoted[i] + ")"
+		}
+	}
+	columns := strings.Join(quoted, ", ")
+	return "insert into submittal_log_facts (id, " + columns + ")" +
+		" select distinct on (submittal_log_id) id, " + columns + " from " + importTable +
+		" order by submittal_log_id, import_row desc" +
+		" on conflict (submittal_log_id) do update set " + strings.Join(sets, ", ")
+}
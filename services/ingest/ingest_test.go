@@ -0,0 +1,135 @@
+package ingest
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"go-lambda-graphql/config"
+	"io"
+	"strings"
+	"testing"
+
+	_ "github.com/lib/pq"
+)
+
+func readAll(t *testing.T, r Reader) ([]Record, []error) {
+	var records []Record
+	var errs []error
+	for {
+		_, rec, err := r.Read()
+		if err == io.EOF {
+			return records, errs
+		}
+		if _, ok := err.(*badRecordError); ok {
+			errs = append(errs, err)
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		records = append(records, rec)
+	}
+}
+
+func TestIngest(t *testing.T) {
+	t.Run("read csv", func(t *testing.T) {
+		r, err := NewCSVReader(strings.NewReader("Submittal Log ID,title,approver_ids\n1,,\"{2,3}\"\n2,a,\"[4]\"\n"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		records, errs := readAll(t, r)
+		if len(records) != 2 || len(errs) != 0 {
+			t.Fatalf("unexpected records %v errors %v", records, errs)
+		}
+		if records[0]["submittal_log_id"] != "1" || records[0]["title"] != nil {
+			t.Errorf("unexpected record %v", records[0])
+		}
+		if _, err := NewCSVReader(strings.NewReader("password\n")); err == nil {
+			t.Errorf("expected unknown column error")
+		}
+	})
+
+	t.Run("read ndjson", func(t *testing.T) {
+		records, errs := readAll(t, NewNDJSONReader(strings.NewReader("{\"submittal_log_id\": 1}\n\nnot json\n{\"title\": \"a\"}\n")))
+		if len(records) != 2 || len(errs) != 1 {
+			t.Errorf("unexpected records %v errors %v", records, errs)
+		}
+	})
+
+	t.Run("convert records", func(t *testing.T) {
+		r := NewNDJSONReader(strings.NewReader(`{"submittal_log_id": 7, "lead_time": "3", "approver_returned_dates": ["2018-03-01", null], "project_department": {"a": 1}, "private": true}`))
+		records, _ := readAll(t, r)
+		values, err := convert(records[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i, c := range Columns {
+			v := values[i]
+			if valuer, ok := v.(driver.Valuer); ok {
+				v, _ = valuer.Value()
+			}
+			switch c.Name {
+			case "submittal_log_id":
+				if v != "7" {
+					t.Errorf("unexpected id %v", v)
+				}
+			case "approver_returned_dates":
+				if v != `{"2018-03-01",NULL}` {
+					t.Errorf("unexpected dates %v", v)
+				}
+			case "approver_ids":
+				if v != "{}" {
+					t.Errorf("expected an empty array, got %v", v)
+				}
+			case "project_department":
+				if v != `{"a":1}` {
+					t.Errorf("unexpected json %v", v)
+				}
+			case "title":
+				if v != nil {
+					t.Errorf("expected null, got %v", v)
+				}
+			}
+		}
+	})
+
+	t.Run("report invalid records", func(t *testing.T) {
+		for _, rec := range []Record{
+			{"title": "no key"},
+			{"submittal_log_id": "1", "lead_time": "soon"},
+			{"submittal_log_id": "1", "approver_ids": "{1,NULL}"},
+			{"submittal_log_id": "1", "unknown": "x"},
+		} {
+			if _, err := convert(rec); err == nil {
+				t.Errorf("expected error for %v", rec)
+			}
+		}
+	})
+}
+
+func TestImportKeepsSoftDeletes(t *testing.T) {
+	if query := upsertSQL([]string{"title", "deleted_at"}); !strings.Contains(query, `"deleted_at" = coalesce(submittal_log_facts."deleted_at", excluded."deleted_at")`) {
+		t.Errorf("expected the deleted_at of existing rows to be kept, got %s", query)
+	}
+
+	db, _ := sql.Open("postgres", config.ConnectionString)
+	const key = 9000000001
+	var id int64
+	err := db.QueryRow(`insert into submittal_log_facts (submittal_log_id, title, deleted_at)
+		values ($1, 'before', now()) returning id`, key).Scan(&id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Exec("delete from submittal_log_facts where id = $1", id)
+
+	if _, err := Import(db, NewNDJSONReader(strings.NewReader(`{"submittal_log_id": 9000000001, "title": "after"}`))); err != nil {
+		t.Fatal(err)
+	}
+	var title string
+	var deleted bool
+	if err := db.QueryRow("select title, deleted_at is not null from submittal_log_facts where id = $1", id).Scan(&title, &deleted); err != nil {
+		t.Fatal(err)
+	}
+	if title != "after" || !deleted {
+		t.Errorf("expected the import to update the title and keep the soft delete, got %q deleted %v", title, deleted)
+	}
+}
@@ -0,0 +1,120 @@
+package ingest
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Record is a row of an export keyed by column name, a nil value is a null
+type Record map[string]interface{}
+
+// Reader reads the records of an export
+type Reader interface {
+	// Read returns the next record with its 1-based row number, or io.EOF after the last one
+	Read() (int, Record, error)
+}
+
+// badRecordError is a record that could not be read, the reader can go on with the next one
+type badRecordError struct {
+	err error
+}
+
+func (e *badRecordError) Error() string {
+	return e.err.Error()
+}
+
+// normalize maps a header or key of an export to a column name
+func normalize(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	return strings.NewReplacer(" ", "_", "-", "_").Replace(name)
+}
+
+type csvReader struct {
+	r      *csv.Reader
+	header []string
+	row    int
+}
+
+// NewCSVReader reads a csv export with a header row naming the columns,
+// empty cells are null and array cells hold a json array or a postgres array literal
+func NewCSVReader(r io.Reader) (Reader, error) {
+	cr := csv.NewReader(r)
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("reading the csv header: %v", err)
+	}
+	for i, name := range header {
+		name = normalize(name)
+		if _, ok := columnsByName[name]; !ok && name != "id" {
+			return nil, fmt.Errorf("unknown column %s", header[i])
+		}
+		header[i] = name
+	}
+	return &csvReader{r: cr, header: header}, nil
+}
+
+func (r *csvReader) Read() (int, Record, error) {
+	cells, err := r.r.Read()
+	if err == io.EOF {
+		return 0, nil, err
+	}
+	r.row++
+	if _, ok := err.(*csv.ParseError); ok {
+		return r.row, nil, &badRecordError{err}
+	}
+	if err != nil {
+		return r.row, nil, err
+	}
+	rec := make(Record, len(cells))
+	for i, cell := range cells {
+		if cell != "" {
+			rec[r.header[i]] = cell
+		}
+	}
+	return r.row, rec, nil
+}
+
+type ndjsonReader struct {
+	s    *bufio.Scanner
+	line int
+}
+
+// maxLine is the longest line an ndjson export can have
+const maxLine = 16 << 20
+
+// NewNDJSONReader reads an export with one json object per line, blank lines are skipped
+func NewNDJSONReader(r io.Reader) Reader {
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 64<<10), maxLine)
+	return &ndjsonReader{s: s}
+}
+
+func (r *ndjsonReader) Read() (int, Record, error) {
+	for r.s.Scan() {
+		r.line++
+		line := bytes.TrimSpace(r.s.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var obj map[string]interface{}
+		d := json.NewDecoder(bytes.NewReader(line))
+		d.UseNumber()
+		if err := d.Decode(&obj); err != nil {
+			return r.line, nil, &badRecordError{fmt.Errorf("invalid json: %v", err)}
+		}
+		rec := make(Record, len(obj))
+		for k, v := range obj {
+			rec[normalize(k)] = v
+		}
+		return r.line, rec, nil
+	}
+	if err := r.s.Err(); err != nil {
+		return r.line, nil, err
+	}
+	return 0, nil, io.EOF
+}
@@ -0,0 +1,59 @@
+package keys
+
+import (
+	"encoding/base64"
+	"go-lambda-graphql/services/generate"
+	"testing"
+)
+
+func TestKeys(t *testing.T) {
+	secret1 := base64.StdEncoding.EncodeToString(generate.GenerateRandomBytes(32))
+	secret2 := base64.StdEncoding.EncodeToString(generate.GenerateRandomBytes(32))
+	raw := `{"current": "b", "keys": [{"kid": "a", "secret": "` + secret1 + `"}, {"kid": "b", "secret": "` + secret2 + `"}]}`
+
+	t.Run("parse key set", func(t *testing.T) {
+		s, err := Parse([]byte(raw))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s.Current().ID != "b" {
+			t.Errorf("expected current key b, got %s", s.Current().ID)
+		}
+		if _, err := s.Lookup("a"); err != nil {
+			t.Errorf("expected old key to still verify")
+		}
+		if _, err := s.Lookup("c"); err == nil {
+			t.Errorf("expected unknown key error")
+		}
+	})
+
+	t.Run("rotate keys", func(t *testing.T) {
+		s, _ := Parse([]byte(raw))
+		if err := s.Remove("b"); err == nil {
+			t.Errorf("expected error removing current key")
+		}
+		s.Add(&Key{ID: "c", Secret: generate.GenerateRandomBytes(32)})
+		if err := s.SetCurrent("c"); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.Remove("a"); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := s.Lookup("a"); err == nil {
+			t.Errorf("expected removed key to be gone")
+		}
+	})
+
+	t.Run("reject bad key sets", func(t *testing.T) {
+		for _, raw := range []string{
+			`{"current": "a", "keys": []}`,
+			`{"current": "a", "keys": [{"kid": "a", "secret": "c2hvcnQ="}]}`,
+			`{"current": "a", "keys": [{"secret": "` + secret1 + `"}]}`,
+			`not json`,
+		} {
+			if _, err := Parse([]byte(raw)); err == nil {
+				t.Errorf("expected error parsing %s", raw)
+			}
+		}
+	})
+}
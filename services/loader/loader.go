@@ -0,0 +1,127 @@
+package loader
+
+import (
+	"sync"
+	"time"
+)
+
+// BatchFunc fetches the values for keys in one round trip, returning values and errors in the order of keys
+type BatchFunc func(keys []int64) ([]interface{}, []error)
+
+// Loader coalesces every Load made within the wait window into a single BatchFunc call
+// and caches the results, it is meant to live for a single request
+type Loader struct {
+	fetch    BatchFunc
+	wait     time.Duration
+	maxBatch int
+
+	mu    sync.Mutex
+	cache map[int64]*result
+	batch *batch
+}
+
+type result struct {
+	done  chan struct{}
+	value interface{}
+	err   error
+}
+
+type batch struct {
+	keys       []int64
+	results    []*result
+	dispatched bool
+}
+
+// New returns a loader that waits up to wait for more keys before fetching, at most maxBatch keys at a time
+func New(fetch BatchFunc, wait time.Duration, maxBatch int) *Loader {
+	return &Loader{
+		fetch:    fetch,
+		wait:     wait,
+		maxBatch: maxBatch,
+		cache:    map[int64]*result{},
+	}
+}
+
+// Load returns the value for key, batched with any other loads in the same window
+func (l *Loader) Load(key int64) (interface{}, error) {
+	r := l.enqueue(key)
+	<-r.done
+	return r.value, r.err
+}
+
+// LoadMany loads several keys in the same batch
+func (l *Loader) LoadMany(keys []int64) ([]interface{}, []error) {
+	pending := make([]*result, len(keys))
+	for i, key := range keys {
+		pending[i] = l.enqueue(key)
+	}
+	values := make([]interface{}, len(keys))
+	errs := make([]error, len(keys))
+	for i, r := range pending {
+		<-r.done
+		values[i], errs[i] = r.value, r.err
+	}
+	return values, errs
+}
+
+// Prime stores a value that was fetched some other way, so loading it does not hit the database
+func (l *Loader) Prime(key int64, value interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	r := &result{done: make(chan struct{}), value: value}
+	close(r.done)
+	l.cache[key] = r
+}
+
+// Clear drops a cached value, e.g. after it was updated
+func (l *Loader) Clear(key int64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.cache, key)
+}
+
+func (l *Loader) enqueue(key int64) *result {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if r, ok := l.cache[key]; ok {
+		return r
+	}
+	r := &result{done: make(chan struct{})}
+	l.cache[key] = r
+	if l.batch == nil {
+		b := &batch{}
+		l.batch = b
+		time.AfterFunc(l.wait, func() { l.dispatch(b) })
+	}
+	l.batch.keys = append(l.batch.keys, key)
+	l.batch.results = append(l.batch.results, r)
+	if l.maxBatch > 0 && len(l.batch.keys) >= l.maxBatch {
+		b := l.batch
+		l.batch = nil
+		go l.dispatch(b)
+	}
+	return r
+}
+
+func (l *Loader) dispatch(b *batch) {
+	l.mu.Lock()
+	if b.dispatched {
+		l.mu.Unlock()
+		return
+	}
+	b.dispatched = true
+	if l.batch == b {
+		l.batch = nil
+	}
+	l.mu.Unlock()
+	values, errs := l.fetch(b.keys)
+	for i, r := range b.results {
+		if i < len(values) {
+			r.value = values[i]
+		}
+		if i < len(errs) {
+			r.err = errs[i]
+		}
+		close(r.done)
+	}
+}
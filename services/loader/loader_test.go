@@ -0,0 +1,79 @@
+package loader
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLoader(t *testing.T) {
+	t.Run("coalesce loads into one batch", func(t *testing.T) {
+		var calls int
+		var mu sync.Mutex
+		l := New(func(keys []int64) ([]interface{}, []error) {
+			mu.Lock()
+			calls++
+			mu.Unlock()
+			values := make([]interface{}, len(keys))
+			for i, key := range keys {
+				values[i] = key * 10
+			}
+			return values, make([]error, len(keys))
+		}, 5*time.Millisecond, 0)
+
+		var wg sync.WaitGroup
+		for i := int64(1); i <= 5; i++ {
+			wg.Add(1)
+			go func(key int64) {
+				defer wg.Done()
+				v, err := l.Load(key)
+				if err != nil || v.(int64) != key*10 {
+					t.Errorf("unexpected result %v %v", v, err)
+				}
+			}(i % 3)
+		}
+		wg.Wait()
+		if calls != 1 {
+			t.Errorf("expected 1 batch, got %d", calls)
+		}
+		l.Load(1)
+		if calls != 1 {
+			t.Errorf("expected cached result, got %d batches", calls)
+		}
+	})
+
+	t.Run("split batches at max size", func(t *testing.T) {
+		var sizes []int
+		var mu sync.Mutex
+		l := New(func(keys []int64) ([]interface{}, []error) {
+			mu.Lock()
+			sizes = append(sizes, len(keys))
+			mu.Unlock()
+			return make([]interface{}, len(keys)), make([]error, len(keys))
+		}, 5*time.Millisecond, 2)
+		l.LoadMany([]int64{1, 2, 3})
+		time.Sleep(10 * time.Millisecond)
+		if len(sizes) != 2 {
+			t.Errorf("expected 2 batches, got %v", sizes)
+		}
+	})
+
+	t.Run("return per key errors and primed values", func(t *testing.T) {
+		l := New(func(keys []int64) ([]interface{}, []error) {
+			errs := make([]error, len(keys))
+			for i := range keys {
+				errs[i] = errors.New("not found")
+			}
+			return make([]interface{}, len(keys)), errs
+		}, time.Millisecond, 0)
+		l.Prime(1, "primed")
+		values, errs := l.LoadMany([]int64{1, 2})
+		if values[0] != "primed" || errs[0] != nil {
+			t.Errorf("expected primed value")
+		}
+		if errs[1] == nil {
+			t.Errorf("expected error")
+		}
+	})
+}
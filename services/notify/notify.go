@@ -0,0 +1,102 @@
+package notify
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Buffer is how many notifications a subscriber can fall behind before new ones are dropped for it
+const Buffer = 16
+
+// pingInterval is how often an idle connection is checked, so a dead one is noticed and reconnected
+const pingInterval = 90 * time.Second
+
+// Listener holds a single postgres connection listening on a fixed set of channels
+type Listener struct {
+	l    *pq.Listener
+	mu   sync.Mutex
+	subs map[string]map[chan string]struct{}
+}
+
+// NewListener connects to postgres and starts listening on the channels
+func NewListener(connectionString string, channels ...string) (*Listener, error) {
+	l := &Listener{
+		l: pq.NewListener(connectionString, time.Second, time.Minute, func(ev pq.ListenerEventType, err error) {
+			if err != nil {
+				log.Println("notify:", err)
+			}
+		}),
+		subs: map[string]map[chan string]struct{}{},
+	}
+	for _, channel := range channels {
+		if err := l.l.Listen(channel); err != nil {
+			l.l.Close()
+			return nil, err
+		}
+		l.subs[channel] = map[chan string]struct{}{}
+	}
+	go l.run()
+	return l, nil
+}
+
+// Subscribe returns the payloads notified on channel until ctx is done, the channel is closed then
+func (l *Listener) Subscribe(ctx context.Context, channel string) <-chan string {
+	c := make(chan string, Buffer)
+	l.mu.Lock()
+	subs, ok := l.subs[channel]
+	if ok {
+		subs[c] = struct{}{}
+	}
+	l.mu.Unlock()
+	if !ok {
+		// nothing is ever notified on a channel the listener does not listen on
+		close(c)
+		return c
+	}
+	go func() {
+		<-ctx.Done()
+		l.mu.Lock()
+		delete(subs, c)
+		l.mu.Unlock()
+		close(c)
+	}()
+	return c
+}
+
+// Close stops listening, the subscribers are closed when their context is done
+func (l *Listener) Close() error {
+	return l.l.Close()
+}
+
+func (l *Listener) run() {
+	for {
+		select {
+		case n, ok := <-l.l.Notify:
+			if !ok {
+				return
+			}
+			// a nil notification means the connection was reestablished, anything sent meanwhile is lost
+			if n != nil {
+				l.dispatch(n.Channel, n.Extra)
+			}
+		case <-time.After(pingInterval):
+			go l.l.Ping()
+		}
+	}
+}
+
+// dispatch hands a payload to every subscriber of channel, a slow subscriber misses it rather than blocking the others
+func (l *Listener) dispatch(channel string, payload string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for c := range l.subs[channel] {
+		select {
+		case c <- payload:
+		default:
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package notify
+
+import (
+	"context"
+	"testing"
+)
+
+func TestListener(t *testing.T) {
+	l := &Listener{subs: map[string]map[chan string]struct{}{"usr_updated": {}}}
+
+	t.Run("fan out to subscribers", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		a, b := l.Subscribe(ctx, "usr_updated"), l.Subscribe(ctx, "usr_updated")
+		l.dispatch("usr_updated", "7")
+		l.dispatch("other", "8")
+		if got := <-a; got != "7" {
+			t.Errorf("unexpected payload %s", got)
+		}
+		if got := <-b; got != "7" {
+			t.Errorf("unexpected payload %s", got)
+		}
+	})
+
+	t.Run("drop when a subscriber falls behind", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		c := l.Subscribe(ctx, "usr_updated")
+		for i := 0; i < Buffer+5; i++ {
+			l.dispatch("usr_updated", "1")
+		}
+		if len(c) != Buffer {
+			t.Errorf("expected %d buffered payloads, got %d", Buffer, len(c))
+		}
+	})
+
+	t.Run("close on cancel", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		c := l.Subscribe(ctx, "usr_updated")
+		cancel()
+		if _, ok := <-c; ok {
+			t.Errorf("expected closed channel")
+		}
+		if _, ok := <-l.Subscribe(context.Background(), "unknown"); ok {
+			t.Errorf("expected closed channel for an unknown channel")
+		}
+	})
+}
@@ -0,0 +1,124 @@
+package persisted
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+// ErrNotFound is returned for a hash no query was stored under, the client has to send the full query
+var ErrNotFound = errors.New("persisted query not found")
+
+// ErrInvalidHash is returned for a hash that is not a hex encoded sha256
+var ErrInvalidHash = errors.New("invalid persisted query hash")
+
+// ErrTooLarge is returned for a query longer than MaxLength, it still runs but is not stored
+var ErrTooLarge = errors.New("persisted query too large")
+
+// ErrReadOnly is returned by the stores that only serve the queries shipped with a build
+var ErrReadOnly = errors.New("persisted queries are read only")
+
+// MaxLength is the longest query a store keeps, in bytes
+const MaxLength = 16 << 10
+
+var validHash = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// checkPut validates what Put is about to store
+func checkPut(hash string, query string) error {
+	if !validHash.MatchString(hash) {
+		return ErrInvalidHash
+	}
+	if len(query) > MaxLength {
+		return ErrTooLarge
+	}
+	return nil
+}
+
+// Store keeps queries by the sha256 of their text, queries never change once stored
+type Store interface {
+	Get(hash string) (string, error)
+	Put(hash string, query string) error
+}
+
+// Hash returns the hex encoded sha256 of a query, as sent by the clients
+func Hash(query string) string {
+	sum := sha256.Sum256([]byte(query))
+	return hex.EncodeToString(sum[:])
+}
+
+// FileStore keeps one <hash>.graphql file per query in Dir, queries can be shipped with a build that way
+type FileStore struct {
+	Dir string
+	// ReadOnly stores serve the shipped queries as an allowlist and never write,
+	// the file system of aws lambda is read only
+	ReadOnly bool
+}
+
+// Get reads the query stored under hash
+func (s *FileStore) Get(hash string) (string, error) {
+	if !validHash.MatchString(hash) {
+		return "", ErrInvalidHash
+	}
+	b, err := ioutil.ReadFile(filepath.Join(s.Dir, hash+".graphql"))
+	if os.IsNotExist(err) {
+		return "", ErrNotFound
+	}
+	return string(b), err
+}
+
+// Put writes the query, through a temporary file so a concurrent Get never reads half of it
+func (s *FileStore) Put(hash string, query string) error {
+	if err := checkPut(hash, query); err != nil {
+		return err
+	}
+	if s.ReadOnly {
+		return ErrReadOnly
+	}
+	f, err := ioutil.TempFile(s.Dir, hash)
+	if err != nil {
+		return err
+	}
+	_, err = f.WriteString(query)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), filepath.Join(s.Dir, hash+".graphql"))
+	}
+	if err != nil {
+		os.Remove(f.Name())
+	}
+	return err
+}
+
+// SQLStore keeps the queries in the persisted_query table
+type SQLStore struct {
+	DB *sql.DB
+}
+
+// Get reads the query stored under hash
+func (s *SQLStore) Get(hash string) (string, error) {
+	if !validHash.MatchString(hash) {
+		return "", ErrInvalidHash
+	}
+	var query string
+	err := s.DB.QueryRow("select query from persisted_query where hash = $1", hash).Scan(&query)
+	if err == sql.ErrNoRows {
+		return "", ErrNotFound
+	}
+	return query, err
+}
+
+// Put stores the query, storing it twice is a no-op
+func (s *SQLStore) Put(hash string, query string) error {
+	if err := checkPut(hash, query); err != nil {
+		return err
+	}
+	_, err := s.DB.Exec("insert into persisted_query (hash, query) values ($1, $2) on conflict (hash) do nothing", hash, query)
+	return err
+}
@@ -0,0 +1,67 @@
+package persisted
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestFileStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "persisted")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	s := &FileStore{Dir: dir}
+	query := "{ viewer { id } }"
+	hash := Hash(query)
+
+	t.Run("hash like the clients", func(t *testing.T) {
+		if Hash("") != "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855" {
+			t.Errorf("unexpected hash of the empty query %s", Hash(""))
+		}
+	})
+
+	t.Run("not found before put", func(t *testing.T) {
+		if _, err := s.Get(hash); err != ErrNotFound {
+			t.Errorf("expected ErrNotFound, got %v", err)
+		}
+	})
+
+	t.Run("round trip", func(t *testing.T) {
+		if err := s.Put(hash, query); err != nil {
+			t.Fatal(err)
+		}
+		if got, err := s.Get(hash); err != nil || got != query {
+			t.Errorf("unexpected query %q %v", got, err)
+		}
+	})
+
+	t.Run("reject large queries", func(t *testing.T) {
+		large := "{" + strings.Repeat(" viewer { id }", MaxLength/14+1) + " }"
+		if err := s.Put(Hash(large), large); err != ErrTooLarge {
+			t.Errorf("expected ErrTooLarge, got %v", err)
+		}
+	})
+
+	t.Run("never write read only stores", func(t *testing.T) {
+		readOnly := &FileStore{Dir: dir, ReadOnly: true}
+		other := "{ other }"
+		if err := readOnly.Put(Hash(other), other); err != ErrReadOnly {
+			t.Errorf("expected ErrReadOnly, got %v", err)
+		}
+		if got, err := readOnly.Get(hash); err != nil || got != query {
+			t.Errorf("unexpected query %q %v", got, err)
+		}
+	})
+
+	t.Run("reject invalid hashes", func(t *testing.T) {
+		if _, err := s.Get("../../etc/passwd"); err != ErrInvalidHash {
+			t.Errorf("expected ErrInvalidHash, got %v", err)
+		}
+		if err := s.Put("ABC", query); err != ErrInvalidHash {
+			t.Errorf("expected ErrInvalidHash, got %v", err)
+		}
+	})
+}
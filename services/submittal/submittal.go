@@ -0,0 +1,184 @@
+package submittal
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// DateFormat is the format of the date array elements of submittal_log_facts
+const DateFormat = "2006-01-02"
+
+// Status is where an approver stands in the review of a submittal
+type Status string
+
+const (
+	// Pending approvers have not returned the submittal and are not past due
+	Pending Status = "PENDING"
+	// Overdue approvers have not returned the submittal and are past due
+	Overdue Status = "OVERDUE"
+	// ReturnedOnTime approvers returned the submittal on or before the due date, or without one
+	ReturnedOnTime Status = "RETURNED_ON_TIME"
+	// ReturnedLate approvers returned the submittal after the due date
+	ReturnedLate Status = "RETURNED_LATE"
+)
+
+// OverdueForSQL matches the submittals with an approver who is overdue,
+// the same rule as Approver.Status evaluated by postgres, its arguments are the approver id and Today,
+// the day is passed in rather than current_date so it does not depend on the time zone of the session
+const OverdueForSQL = `exists (
+	select 1 from unnest(approver_ids, approver_due_dates, approver_returned_dates) as a(id, due, returned)
+	where a.id = ? and a.returned is null and a.due < ?::date
+)`
+
+// Dates is a date[] column, unlike a StringArray it scans the null elements
+// that keep the approver arrays aligned, they are left nil
+type Dates []*time.Time
+
+// Scan implements the sql.Scanner interface
+func (d *Dates) Scan(src interface{}) error {
+	var l []sql.NullString
+	if err := pq.Array(&l).Scan(src); err != nil {
+		return err
+	}
+	dates := make(Dates, len(l))
+	for i, s := range l {
+		if !s.Valid {
+			continue
+		}
+		t, err := time.Parse(DateFormat, s.String)
+		if err != nil {
+			return err
+		}
+		dates[i] = &t
+	}
+	*d = dates
+	return nil
+}
+
+// Party is a user or vendor a submittal is waiting on
+type Party struct {
+	ID   int64
+	Name string
+	Due  *time.Time
+}
+
+// Approver is a step of the approval workflow of a submittal
+type Approver struct {
+	Party
+	VendorID int64
+	Response string
+	Sent     *time.Time
+	Returned *time.Time
+}
+
+// Today returns the current date, days are counted in UTC like the dates stored in postgres
+func Today() time.Time {
+	return day(time.Now().UTC())
+}
+
+// day truncates t to midnight
+func day(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// Status returns the status of the approver on the given day
+func (a Approver) Status(today time.Time) Status {
+	if a.Returned != nil {
+		if a.Due != nil && day(*a.Returned).After(day(*a.Due)) {
+			return ReturnedLate
+		}
+		return ReturnedOnTime
+	}
+	if a.Due != nil && day(today).After(day(*a.Due)) {
+		return Overdue
+	}
+	return Pending
+}
+
+// DaysOverdue returns how many days past due the approver is, or returned the submittal, zero when on time
+func (a Approver) DaysOverdue(today time.Time) int {
+	if a.Due == nil {
+		return 0
+	}
+	end := today
+	if a.Returned != nil {
+		end = *a.Returned
+	}
+	days := int(day(end).Sub(day(*a.Due)).Hours() / 24)
+	if days < 0 {
+		return 0
+	}
+	return days
+}
+
+// Approvers zips the parallel approver arrays of a submittal,
+// missing elements are left empty
+func Approvers(ids []int64, vendorIDs []int64, names, responses []string, sent, due, returned Dates) []Approver {
+	approvers := make([]Approver, len(ids))
+	for i, id := range ids {
+		approvers[i] = Approver{
+			Party: Party{
+				ID:   id,
+				Name: str(names, i),
+				Due:  date(due, i),
+			},
+			VendorID: int64At(vendorIDs, i),
+			Response: str(responses, i),
+			Sent:     date(sent, i),
+			Returned: date(returned, i),
+		}
+	}
+	return approvers
+}
+
+// BallInCourt returns the parties a submittal is waiting on, the ball in court columns when they are set,
+// otherwise the approvers that have not returned it yet
+func BallInCourt(ids []int64, names []string, due Dates, approvers []Approver) []Party {
+	var parties []Party
+	for i, id := range ids {
+		parties = append(parties, Party{ID: id, Name: str(names, i), Due: date(due, i)})
+	}
+	if len(parties) > 0 {
+		return parties
+	}
+	for _, a := range approvers {
+		if a.Returned == nil {
+			parties = append(parties, a.Party)
+		}
+	}
+	return parties
+}
+
+// DaysOverdue returns the days overdue of the most overdue approver still holding the submittal
+func DaysOverdue(approvers []Approver, today time.Time) int {
+	max := 0
+	for _, a := range approvers {
+		if a.Status(today) == Overdue && a.DaysOverdue(today) > max {
+			max = a.DaysOverdue(today)
+		}
+	}
+	return max
+}
+
+func str(l []string, i int) string {
+	if i < len(l) {
+		return l[i]
+	}
+	return ""
+}
+
+func int64At(l []int64, i int) int64 {
+	if i < len(l) {
+		return l[i]
+	}
+	return 0
+}
+
+func date(l Dates, i int) *time.Time {
+	if i < len(l) {
+		return l[i]
+	}
+	return nil
+}
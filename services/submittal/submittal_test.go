@@ -0,0 +1,64 @@
+package submittal
+
+import (
+	"testing"
+	"time"
+)
+
+func dates(t *testing.T, array string) Dates {
+	var d Dates
+	if err := d.Scan([]byte(array)); err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+func TestSubmittal(t *testing.T) {
+	today := time.Date(2018, 3, 10, 15, 0, 0, 0, time.UTC)
+	approvers := Approvers(
+		[]int64{1, 2, 3, 4, 5},
+		nil,
+		[]string{"early", "late", "waiting", "behind"},
+		nil,
+		nil,
+		dates(t, "{2018-03-05,2018-03-05,2018-03-10,2018-03-07,NULL}"),
+		dates(t, "{2018-03-05,2018-03-08,NULL,NULL,NULL}"),
+	)
+
+	t.Run("null dates", func(t *testing.T) {
+		d := dates(t, "{NULL,2018-03-01}")
+		if len(d) != 2 || d[0] != nil || d[1] == nil || d[1].Day() != 1 {
+			t.Errorf("unexpected dates %v", d)
+		}
+		var invalid Dates
+		if err := invalid.Scan([]byte("{2018-13-01}")); err == nil {
+			t.Errorf("expected an invalid date to fail")
+		}
+	})
+
+	t.Run("approver status", func(t *testing.T) {
+		expected := []Status{ReturnedOnTime, ReturnedLate, Pending, Overdue, Pending}
+		for i, a := range approvers {
+			if s := a.Status(today); s != expected[i] {
+				t.Errorf("approver %d: expected %s, got %s", a.ID, expected[i], s)
+			}
+		}
+		if d := approvers[1].DaysOverdue(today); d != 3 {
+			t.Errorf("expected the late approver to be 3 days late, got %d", d)
+		}
+		if d := DaysOverdue(approvers, today); d != 3 {
+			t.Errorf("expected the submittal to be 3 days overdue, got %d", d)
+		}
+	})
+
+	t.Run("ball in court", func(t *testing.T) {
+		parties := BallInCourt(nil, nil, nil, approvers)
+		if len(parties) != 3 || parties[0].Name != "waiting" || parties[2].ID != 5 {
+			t.Errorf("unexpected parties %+v", parties)
+		}
+		parties = BallInCourt([]int64{9}, []string{"vendor"}, dates(t, "{2018-03-12}"), approvers)
+		if len(parties) != 1 || parties[0].ID != 9 || parties[0].Due == nil {
+			t.Errorf("unexpected parties %+v", parties)
+		}
+	})
+}
@@ -0,0 +1,84 @@
+package trend
+
+import (
+	"go-lambda-graphql/services/apperr"
+	"time"
+)
+
+// DateFormat keys the buckets by calendar date
+const DateFormat = "2006-01-02"
+
+// MaxBuckets is the longest series a trend can have
+const MaxBuckets = 1000
+
+// Interval is the width of a bucket
+type Interval string
+
+const (
+	// Day buckets
+	Day Interval = "day"
+	// Week buckets start on monday, like postgres date_trunc
+	Week Interval = "week"
+	// Month buckets
+	Month Interval = "month"
+)
+
+// Point is the count of a single bucket
+type Point struct {
+	Bucket time.Time
+	Count  int
+}
+
+// Truncate returns the start of the bucket the date falls in
+func Truncate(t time.Time, interval Interval) time.Time {
+	t = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	switch interval {
+	case Week:
+		// time.Sunday is 0, go back to the monday before
+		return t.AddDate(0, 0, -(int(t.Weekday())+6)%7)
+	case Month:
+		return t.AddDate(0, 0, 1-t.Day())
+	}
+	return t
+}
+
+// Buckets lists the starts of the buckets covering from to to, both included
+func Buckets(from, to time.Time, interval Interval) ([]time.Time, error) {
+	switch interval {
+	case Day, Week, Month:
+	default:
+		return nil, apperr.Errorf(apperr.ValidationFailed, "unknown interval %s", interval)
+	}
+	if to.Before(from) {
+		return nil, apperr.New(apperr.ValidationFailed, "the trend ends before it starts")
+	}
+	var buckets []time.Time
+	end := Truncate(to, interval)
+	for b := Truncate(from, interval); !b.After(end); b = next(b, interval) {
+		if len(buckets) == MaxBuckets {
+			return nil, apperr.Errorf(apperr.ValidationFailed, "a trend cannot have more than %d buckets", MaxBuckets)
+		}
+		buckets = append(buckets, b)
+	}
+	return buckets, nil
+}
+
+func next(t time.Time, interval Interval) time.Time {
+	switch interval {
+	case Week:
+		return t.AddDate(0, 0, 7)
+	case Month:
+		return t.AddDate(0, 1, 0)
+	}
+	return t.AddDate(0, 0, 1)
+}
+
+// Fill returns a point for every bucket, counts are keyed by the DateFormat of their bucket
+// and the buckets without activity count zero
+func Fill(buckets []time.Time, counts map[string]int) []Point {
+	points := make([]Point, len(buckets))
+	for i, b := range buckets {
+		points[i] = Point{Bucket: b, Count: counts[b.Format(DateFormat)]}
+	}
+	return points
+}
@@ -0,0 +1,54 @@
+package trend
+
+import (
+	"testing"
+	"time"
+)
+
+func date(s string) time.Time {
+	t, _ := time.Parse(DateFormat, s)
+	return t
+}
+
+func TestTrend(t *testing.T) {
+	t.Run("truncate to the bucket start", func(t *testing.T) {
+		for _, c := range []struct {
+			in       string
+			interval Interval
+			out      string
+		}{
+			{"2018-03-14", Day, "2018-03-14"},
+			{"2018-03-14", Week, "2018-03-12"},
+			{"2018-03-18", Week, "2018-03-12"},
+			{"2018-03-12", Week, "2018-03-12"},
+			{"2018-03-14", Month, "2018-03-01"},
+		} {
+			if b := Truncate(date(c.in), c.interval).Format(DateFormat); b != c.out {
+				t.Errorf("%s by %s: expected %s, got %s", c.in, c.interval, c.out, b)
+			}
+		}
+	})
+
+	t.Run("fill the gaps", func(t *testing.T) {
+		buckets, err := Buckets(date("2018-01-31"), date("2018-04-02"), Month)
+		if err != nil {
+			t.Fatal(err)
+		}
+		points := Fill(buckets, map[string]int{"2018-02-01": 3})
+		if len(points) != 4 || points[0].Count != 0 || points[1].Count != 3 || points[3].Bucket.Format(DateFormat) != "2018-04-01" {
+			t.Errorf("unexpected points %v", points)
+		}
+	})
+
+	t.Run("reject invalid ranges", func(t *testing.T) {
+		if _, err := Buckets(date("2018-02-01"), date("2018-01-01"), Day); err == nil {
+			t.Errorf("expected error for a reversed range")
+		}
+		if _, err := Buckets(date("2000-01-01"), date("2018-01-01"), Day); err == nil {
+			t.Errorf("expected error for too many buckets")
+		}
+		if _, err := Buckets(date("2018-01-01"), date("2018-01-02"), "hour"); err == nil {
+			t.Errorf("expected error for an unknown interval")
+		}
+	})
+}